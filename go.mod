@@ -3,7 +3,9 @@ module github.com/glanceapp/glance
 go 1.22.5
 
 require (
+	github.com/andybalholm/cascadia v1.3.2
 	github.com/antchfx/htmlquery v1.3.3
+	github.com/antchfx/xpath v1.3.2
 	github.com/mmcdole/gofeed v1.3.0
 	github.com/sashabaranov/go-openai v1.35.6
 	golang.org/x/net v0.27.0
@@ -13,8 +15,6 @@ require (
 
 require (
 	github.com/PuerkitoBio/goquery v1.9.2 // indirect
-	github.com/andybalholm/cascadia v1.3.2 // indirect
-	github.com/antchfx/xpath v1.3.2 // indirect
 	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/mmcdole/goxpp v1.1.1 // indirect
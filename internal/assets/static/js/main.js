@@ -10,8 +10,8 @@ async function fetchPageContent(pageData) {
     return content;
 }
 
-function setupCarousels() {
-    const carouselElements = document.getElementsByClassName("carousel-container");
+function setupCarousels(root = document) {
+    const carouselElements = root.getElementsByClassName("carousel-container");
 
     if (carouselElements.length == 0) {
         return;
@@ -188,8 +188,8 @@ function setupSearchBoxes() {
     }
 }
 
-function setupDynamicRelativeTime() {
-    const elements = document.querySelectorAll("[data-dynamic-relative-time]");
+function setupDynamicRelativeTime(root = document) {
+    const elements = root.querySelectorAll("[data-dynamic-relative-time]");
     const updateInterval = 60 * 1000;
     let lastUpdateTime = Date.now();
 
@@ -230,8 +230,8 @@ function setupDynamicRelativeTime() {
     });
 }
 
-function setupGroups() {
-    const groups = document.getElementsByClassName("widget-type-group");
+function setupGroups(root = document) {
+    const groups = root.getElementsByClassName("widget-type-group");
 
     if (groups.length == 0) {
         return;
@@ -240,7 +240,8 @@ function setupGroups() {
     for (let g = 0; g < groups.length; g++) {
         const group = groups[g];
         const titles = group.getElementsByClassName("widget-header")[0].children;
-        const tabs = group.getElementsByClassName("widget-group-contents")[0].children;
+        const contents = group.getElementsByClassName("widget-group-contents")[0];
+        const tabs = contents.children;
         let current = 0;
 
         for (let t = 0; t < titles.length; t++) {
@@ -265,13 +266,286 @@ function setupGroups() {
 
                 title.classList.add("widget-group-title-current");
                 tabs[t].classList.add("widget-group-content-current");
+
+                const lazyWidget = tabs[t].querySelector(":scope > .lazy-widget");
+
+                if (lazyWidget !== null) {
+                    loadLazyWidget(contents.dataset.widgetId, lazyWidget);
+                }
+            });
+        }
+    }
+}
+
+function setupAccordions(root = document) {
+    const accordions = root.getElementsByClassName("widget-accordion");
+
+    for (let a = 0; a < accordions.length; a++) {
+        const accordion = accordions[a];
+        const items = accordion.querySelectorAll(":scope > .widget-accordion-item");
+
+        for (let i = 0; i < items.length; i++) {
+            const item = items[i];
+
+            item.addEventListener("toggle", () => {
+                if (!item.open) {
+                    return;
+                }
+
+                const lazyWidget = item.querySelector(":scope > .widget-accordion-content > .lazy-widget");
+
+                if (lazyWidget !== null) {
+                    loadLazyWidget(accordion.dataset.widgetId, lazyWidget);
+                }
             });
         }
     }
 }
 
-function setupLazyImages() {
-    const images = document.querySelectorAll("img[loading=lazy]");
+async function loadLazyWidget(widgetId, placeholder) {
+    if (placeholder.dataset.loading !== undefined) {
+        return;
+    }
+
+    placeholder.dataset.loading = "";
+
+    try {
+        const response = await fetch(`${pageData.baseURL}/api/widgets/${widgetId}/${placeholder.dataset.lazyWidgetPath}`);
+
+        if (!response.ok) {
+            throw new Error(`unexpected status code ${response.status}`);
+        }
+
+        const content = await response.text();
+        const parent = placeholder.parentElement;
+
+        placeholder.outerHTML = content;
+        setupWidgetContent(parent);
+    } catch (e) {
+        // allow retrying the next time the widget is opened
+        console.error(e);
+        delete placeholder.dataset.loading;
+    }
+}
+
+function setupRSSReadTracking(root = document) {
+    const headers = root.getElementsByClassName("rss-unread-header");
+
+    for (let h = 0; h < headers.length; h++) {
+        const header = headers[h];
+        const widget = header.closest(".widget");
+        const markReadURL = `${pageData.baseURL}/api/widgets/${header.dataset.widgetId}/mark-read`;
+        const countElement = header.querySelector("[data-rss-unread-count]");
+        const button = header.getElementsByClassName("rss-mark-all-read")[0];
+
+        const markItemAsRead = (item) => {
+            const dot = item.getElementsByClassName("rss-unread-dot")[0];
+
+            if (dot === undefined) {
+                return false;
+            }
+
+            dot.remove();
+
+            return true;
+        };
+
+        const decrementCount = () => {
+            const count = Math.max(0, parseInt(countElement.textContent) - 1);
+            countElement.textContent = count;
+            button.disabled = count == 0;
+        };
+
+        const items = widget.querySelectorAll("[data-rss-item-key]");
+
+        for (let i = 0; i < items.length; i++) {
+            const item = items[i];
+
+            item.addEventListener("click", (event) => {
+                if (event.target.closest("a, .rss-reader-button") === null || !markItemAsRead(item)) {
+                    return;
+                }
+
+                const data = new FormData();
+                data.append("key", item.dataset.rssItemKey);
+                navigator.sendBeacon(markReadURL, data);
+                decrementCount();
+            });
+        }
+
+        button.addEventListener("click", async () => {
+            button.disabled = true;
+
+            try {
+                const response = await fetch(markReadURL, { method: "POST" });
+
+                if (!response.ok) {
+                    throw new Error(`unexpected status code ${response.status}`);
+                }
+            } catch (e) {
+                console.error(e);
+                button.disabled = false;
+                return;
+            }
+
+            for (let i = 0; i < items.length; i++) {
+                markItemAsRead(items[i]);
+            }
+
+            countElement.textContent = 0;
+        });
+    }
+}
+
+function openReaderView(url, errorMessage) {
+    const overlay = document.createElement("div");
+    overlay.classList.add("reader-overlay");
+
+    const dialog = document.createElement("div");
+    dialog.classList.add("reader-dialog");
+
+    const closeButton = document.createElement("button");
+    closeButton.classList.add("reader-close");
+    closeButton.textContent = "×";
+    closeButton.title = "Close";
+
+    const loadingIcon = document.createElement("div");
+    loadingIcon.classList.add("loading-icon");
+
+    dialog.append(closeButton, loadingIcon);
+    overlay.append(dialog);
+
+    const close = () => {
+        overlay.remove();
+        document.body.style.removeProperty("overflow");
+        document.removeEventListener("keydown", handleKeyDown);
+    };
+
+    const handleKeyDown = (event) => {
+        if (event.key == "Escape") {
+            close();
+        }
+    };
+
+    closeButton.addEventListener("click", close);
+    overlay.addEventListener("click", (event) => {
+        if (event.target === overlay) {
+            close();
+        }
+    });
+    document.addEventListener("keydown", handleKeyDown);
+
+    document.body.style.overflow = "hidden";
+    document.body.append(overlay);
+
+    fetch(url)
+        .then((response) => {
+            if (!response.ok) {
+                throw new Error(`unexpected status code ${response.status}`);
+            }
+
+            return response.text();
+        })
+        .then((content) => {
+            loadingIcon.remove();
+            dialog.insertAdjacentHTML("beforeend", content);
+        })
+        .catch((e) => {
+            console.error(e);
+            loadingIcon.remove();
+
+            const error = document.createElement("p");
+            error.classList.add("color-negative");
+            error.textContent = errorMessage;
+            dialog.append(error);
+        });
+}
+
+function setupReaderView(root = document) {
+    const buttons = root.getElementsByClassName("rss-reader-button");
+
+    for (let i = 0; i < buttons.length; i++) {
+        const button = buttons[i];
+
+        button.addEventListener("click", () => {
+            openReaderView(
+                `${pageData.baseURL}/api/widgets/${button.dataset.widgetId}/reader?link=${encodeURIComponent(button.dataset.readerLink)}`,
+                "Could not load the article"
+            );
+        });
+    }
+
+    const commentsButtons = root.getElementsByClassName("forum-post-comments-button");
+
+    for (let i = 0; i < commentsButtons.length; i++) {
+        const button = commentsButtons[i];
+
+        button.addEventListener("click", () => {
+            openReaderView(
+                `${pageData.baseURL}/api/widgets/${button.dataset.widgetId}/comments?id=${encodeURIComponent(button.dataset.postId)}`,
+                "Could not load the comments"
+            );
+        });
+    }
+}
+
+function setupPodcastPlayers(root = document) {
+    const players = root.getElementsByClassName("rss-podcast-player");
+
+    for (let i = 0; i < players.length; i++) {
+        const player = players[i];
+        const playbackURL = `${pageData.baseURL}/api/widgets/${player.dataset.widgetId}/playback`;
+        let savedPosition = parseInt(player.dataset.playbackPosition) || 0;
+
+        const savePosition = (position) => {
+            position = Math.floor(position);
+
+            if (position == savedPosition) {
+                return;
+            }
+
+            savedPosition = position;
+
+            const data = new FormData();
+            data.append("key", player.dataset.episodeKey);
+            data.append("position", position);
+            navigator.sendBeacon(playbackURL, data);
+        };
+
+        player.addEventListener("loadedmetadata", () => {
+            if (savedPosition > 0 && savedPosition < player.duration - 5) {
+                player.currentTime = savedPosition;
+            }
+        });
+
+        player.addEventListener("play", () => {
+            const others = document.getElementsByClassName("rss-podcast-player");
+
+            for (let j = 0; j < others.length; j++) {
+                if (others[j] !== player) {
+                    others[j].pause();
+                }
+            }
+        });
+
+        player.addEventListener("timeupdate", () => {
+            if (Math.abs(player.currentTime - savedPosition) >= 15) {
+                savePosition(player.currentTime);
+            }
+        });
+
+        player.addEventListener("pause", () => {
+            if (!player.ended) {
+                savePosition(player.currentTime);
+            }
+        });
+
+        player.addEventListener("ended", () => savePosition(0));
+    }
+}
+
+function setupLazyImages(root = document) {
+    const images = root.querySelectorAll("img[loading=lazy]");
 
     if (images.length == 0) {
         return;
@@ -345,8 +619,8 @@ function attachExpandToggleButton(collapsibleContainer) {
 };
 
 
-function setupCollapsibleLists() {
-    const collapsibleLists = document.querySelectorAll(".list.collapsible-container");
+function setupCollapsibleLists(root = document) {
+    const collapsibleLists = root.querySelectorAll(".list.collapsible-container");
 
     if (collapsibleLists.length == 0) {
         return;
@@ -379,8 +653,8 @@ function setupCollapsibleLists() {
     }
 }
 
-function setupCollapsibleGrids() {
-    const collapsibleGridElements = document.querySelectorAll(".cards-grid.collapsible-container");
+function setupCollapsibleGrids(root = document) {
+    const collapsibleGridElements = root.querySelectorAll(".cards-grid.collapsible-container");
 
     if (collapsibleGridElements.length == 0) {
         return;
@@ -454,9 +728,49 @@ function setupCollapsibleGrids() {
     }
 }
 
+function setupThemeSchedule() {
+    const root = document.documentElement;
+
+    if (root.dataset.themeSwitches === undefined) {
+        return;
+    }
+
+    const switches = root.dataset.themeSwitches.split(" ").filter((s) => s != "").map((s) => {
+        const [palette, timestamp] = s.split(":");
+        return { palette: palette, at: parseInt(timestamp) * 1000 };
+    });
+
+    const applyPalette = (palette) => {
+        root.classList.remove("theme-day", "theme-night");
+        root.classList.add("theme-" + palette);
+
+        if (root.dataset["theme" + (palette == "day" ? "Day" : "Night") + "Light"] === "true") {
+            root.classList.add("light-scheme");
+        } else {
+            root.classList.remove("light-scheme");
+        }
+    };
+
+    for (let i = 0; i < switches.length; i++) {
+        const delay = switches[i].at - Date.now();
+
+        if (delay <= 0) {
+            continue;
+        }
+
+        setTimeout(() => applyPalette(switches[i].palette), delay);
+    }
+}
+
 const contentReadyCallbacks = [];
+let contentReady = false;
 
 function afterContentReady(callback) {
+    if (contentReady) {
+        callback();
+        return;
+    }
+
     contentReadyCallbacks.push(callback);
 }
 
@@ -507,8 +821,8 @@ function timeInZone(now, zone) {
     return { time: timeInZone, diffInHours: diffInHours };
 }
 
-function setupClocks() {
-    const clocks = document.getElementsByClassName('clock');
+function setupClocks(root = document) {
+    const clocks = root.getElementsByClassName('clock');
 
     if (clocks.length == 0) {
         return;
@@ -566,6 +880,22 @@ function setupClocks() {
     updateClocks();
 }
 
+// Sets up widgets whose content was loaded after the page, such as lazily loaded tabs
+function setupWidgetContent(root) {
+    setupPopovers(root);
+    setupClocks(root);
+    setupCarousels(root);
+    setupCollapsibleLists(root);
+    setupCollapsibleGrids(root);
+    setupGroups(root);
+    setupAccordions(root);
+    setupRSSReadTracking(root);
+    setupReaderView(root);
+    setupPodcastPlayers(root);
+    setupDynamicRelativeTime(root);
+    setupLazyImages(root);
+}
+
 async function setupPage() {
     const pageElement = document.getElementById("page");
     const pageContentElement = document.getElementById("page-content");
@@ -575,16 +905,22 @@ async function setupPage() {
 
     try {
         setupPopovers();
+        setupThemeSchedule();
         setupClocks()
         setupCarousels();
         setupSearchBoxes();
         setupCollapsibleLists();
         setupCollapsibleGrids();
         setupGroups();
+        setupAccordions();
+        setupRSSReadTracking();
+        setupReaderView();
+        setupPodcastPlayers();
         setupDynamicRelativeTime();
         setupLazyImages();
     } finally {
         pageElement.classList.add("content-ready");
+        contentReady = true;
 
         for (let i = 0; i < contentReadyCallbacks.length; i++) {
             contentReadyCallbacks[i]();
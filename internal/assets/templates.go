@@ -1,9 +1,13 @@
 package assets
 
 import (
+	"errors"
 	"fmt"
 	"html/template"
+	"io/fs"
+	"log/slog"
 	"math"
+	"os"
 	"strconv"
 	"time"
 
@@ -12,38 +16,88 @@ import (
 )
 
 var (
-	PageTemplate                  = compileTemplate("page.html", "document.html", "page-style-overrides.gotmpl")
-	PageContentTemplate           = compileTemplate("content.html")
-	CalendarTemplate              = compileTemplate("calendar.html", "widget-base.html")
-	ClockTemplate                 = compileTemplate("clock.html", "widget-base.html")
-	BookmarksTemplate             = compileTemplate("bookmarks.html", "widget-base.html")
-	IFrameTemplate                = compileTemplate("iframe.html", "widget-base.html")
-	WeatherTemplate               = compileTemplate("weather.html", "widget-base.html")
-	ForumPostsTemplate            = compileTemplate("forum-posts.html", "widget-base.html")
-	RedditCardsHorizontalTemplate = compileTemplate("reddit-horizontal-cards.html", "widget-base.html")
-	RedditCardsVerticalTemplate   = compileTemplate("reddit-vertical-cards.html", "widget-base.html")
-	ReleasesTemplate              = compileTemplate("releases.html", "widget-base.html")
-	ChangeDetectionTemplate       = compileTemplate("change-detection.html", "widget-base.html")
-	VideosTemplate                = compileTemplate("videos.html", "widget-base.html", "video-card-contents.html")
-	VideosGridTemplate            = compileTemplate("videos-grid.html", "widget-base.html", "video-card-contents.html")
-	MarketsTemplate               = compileTemplate("markets.html", "widget-base.html")
-	RSSListTemplate               = compileTemplate("rss-list.html", "widget-base.html")
-	RSSDetailedListTemplate       = compileTemplate("rss-detailed-list.html", "widget-base.html")
-	RSSHorizontalCardsTemplate    = compileTemplate("rss-horizontal-cards.html", "widget-base.html")
-	RSSHorizontalCards2Template   = compileTemplate("rss-horizontal-cards-2.html", "widget-base.html")
-	MonitorTemplate               = compileTemplate("monitor.html", "widget-base.html")
-	TwitchGamesListTemplate       = compileTemplate("twitch-games-list.html", "widget-base.html")
-	TwitchChannelsTemplate        = compileTemplate("twitch-channels.html", "widget-base.html")
-	RepositoryTemplate            = compileTemplate("repository.html", "widget-base.html")
-	SearchTemplate                = compileTemplate("search.html", "widget-base.html")
-	ExtensionTemplate             = compileTemplate("extension.html", "widget-base.html")
-	GroupTemplate                 = compileTemplate("group.html", "widget-base.html")
-	DNSStatsTemplate              = compileTemplate("dns-stats.html", "widget-base.html")
+	PageTemplate                  *template.Template
+	PageContentTemplate           *template.Template
+	CalendarTemplate              *template.Template
+	ClockTemplate                 *template.Template
+	BookmarksTemplate             *template.Template
+	IFrameTemplate                *template.Template
+	WeatherTemplate               *template.Template
+	ForumPostsTemplate            *template.Template
+	HackerNewsCommentsTemplate    *template.Template
+	DigestTemplate                *template.Template
+	RedditCardsHorizontalTemplate *template.Template
+	RedditCardsVerticalTemplate   *template.Template
+	ReleasesTemplate              *template.Template
+	ChangeDetectionTemplate       *template.Template
+	VideosTemplate                *template.Template
+	VideosGridTemplate            *template.Template
+	MarketsTemplate               *template.Template
+	RSSListTemplate               *template.Template
+	RSSDetailedListTemplate       *template.Template
+	RSSHorizontalCardsTemplate    *template.Template
+	RSSHorizontalCards2Template   *template.Template
+	RSSPodcastTemplate            *template.Template
+	RSSReaderTemplate             *template.Template
+	MonitorTemplate               *template.Template
+	TwitchGamesListTemplate       *template.Template
+	TwitchChannelsTemplate        *template.Template
+	RepositoryTemplate            *template.Template
+	SearchTemplate                *template.Template
+	ExtensionTemplate             *template.Template
+	GroupTemplate                 *template.Template
+	AccordionTemplate             *template.Template
+	SplitTemplate                 *template.Template
+	DNSStatsTemplate              *template.Template
+	CustomAPITemplate             *template.Template
 )
 
+type templateDefinition struct {
+	target **template.Template
+	files  []string
+}
+
+var templateDefinitions = []templateDefinition{
+	{&PageTemplate, []string{"page.html", "document.html", "page-style-overrides.gotmpl"}},
+	{&PageContentTemplate, []string{"content.html"}},
+	{&CalendarTemplate, []string{"calendar.html", "widget-base.html"}},
+	{&ClockTemplate, []string{"clock.html", "widget-base.html"}},
+	{&BookmarksTemplate, []string{"bookmarks.html", "widget-base.html"}},
+	{&IFrameTemplate, []string{"iframe.html", "widget-base.html"}},
+	{&WeatherTemplate, []string{"weather.html", "widget-base.html"}},
+	{&ForumPostsTemplate, []string{"forum-posts.html", "widget-base.html"}},
+	{&HackerNewsCommentsTemplate, []string{"hacker-news-comments.html"}},
+	{&DigestTemplate, []string{"digest.html", "widget-base.html"}},
+	{&RedditCardsHorizontalTemplate, []string{"reddit-horizontal-cards.html", "widget-base.html"}},
+	{&RedditCardsVerticalTemplate, []string{"reddit-vertical-cards.html", "widget-base.html"}},
+	{&ReleasesTemplate, []string{"releases.html", "widget-base.html"}},
+	{&ChangeDetectionTemplate, []string{"change-detection.html", "widget-base.html"}},
+	{&VideosTemplate, []string{"videos.html", "widget-base.html", "video-card-contents.html"}},
+	{&VideosGridTemplate, []string{"videos-grid.html", "widget-base.html", "video-card-contents.html"}},
+	{&MarketsTemplate, []string{"markets.html", "widget-base.html"}},
+	{&RSSListTemplate, []string{"rss-list.html", "widget-base.html", "rss-common.html"}},
+	{&RSSDetailedListTemplate, []string{"rss-detailed-list.html", "widget-base.html", "rss-common.html"}},
+	{&RSSHorizontalCardsTemplate, []string{"rss-horizontal-cards.html", "widget-base.html", "rss-common.html"}},
+	{&RSSHorizontalCards2Template, []string{"rss-horizontal-cards-2.html", "widget-base.html", "rss-common.html"}},
+	{&RSSPodcastTemplate, []string{"rss-podcast.html", "widget-base.html", "rss-common.html"}},
+	{&RSSReaderTemplate, []string{"rss-reader.html"}},
+	{&MonitorTemplate, []string{"monitor.html", "widget-base.html"}},
+	{&TwitchGamesListTemplate, []string{"twitch-games-list.html", "widget-base.html"}},
+	{&TwitchChannelsTemplate, []string{"twitch-channels.html", "widget-base.html"}},
+	{&RepositoryTemplate, []string{"repository.html", "widget-base.html"}},
+	{&SearchTemplate, []string{"search.html", "widget-base.html"}},
+	{&ExtensionTemplate, []string{"extension.html", "widget-base.html"}},
+	{&GroupTemplate, []string{"group.html", "widget-base.html", "lazy-widget.html"}},
+	{&AccordionTemplate, []string{"accordion.html", "widget-base.html", "lazy-widget.html"}},
+	{&SplitTemplate, []string{"split.html", "widget-base.html"}},
+	{&DNSStatsTemplate, []string{"dns-stats.html", "widget-base.html"}},
+	{&CustomAPITemplate, []string{"custom-api.html", "widget-base.html"}},
+}
+
 var globalTemplateFunctions = template.FuncMap{
 	"relativeTime":      relativeTimeSince,
 	"formatViewerCount": formatViewerCount,
+	"formatDuration":    formatDuration,
 	"formatNumber":      intl.Sprint,
 	"absInt": func(i int) int {
 		return int(math.Abs(float64(i)))
@@ -56,16 +110,97 @@ var globalTemplateFunctions = template.FuncMap{
 	},
 }
 
-func compileTemplate(primary string, dependencies ...string) *template.Template {
-	t, err := template.New(primary).
+func compileTemplate(files fs.FS, primary string, dependencies ...string) (*template.Template, error) {
+	return template.New(primary).
 		Funcs(globalTemplateFunctions).
-		ParseFS(TemplateFS, append([]string{primary}, dependencies...)...)
+		ParseFS(files, append([]string{primary}, dependencies...)...)
+}
 
-	if err != nil {
+// Only assigns the templates once all of them have compiled successfully so that
+// a broken override doesn't leave things in a partially updated state
+func compileTemplates(files fs.FS) error {
+	compiled := make([]*template.Template, len(templateDefinitions))
+
+	for i := range templateDefinitions {
+		t, err := compileTemplate(files, templateDefinitions[i].files[0], templateDefinitions[i].files[1:]...)
+
+		if err != nil {
+			return err
+		}
+
+		compiled[i] = t
+	}
+
+	for i := range templateDefinitions {
+		*templateDefinitions[i].target = compiled[i]
+	}
+
+	return nil
+}
+
+func init() {
+	if err := compileTemplates(TemplateFS); err != nil {
 		panic(err)
 	}
+}
+
+// Files in the given directory with the same name as one of the built-in
+// templates take precedence over it, everything else is read from the embedded files
+type templateOverridesFS struct {
+	overrides fs.FS
+	embedded  fs.FS
+}
+
+func (o *templateOverridesFS) Open(name string) (fs.File, error) {
+	file, err := o.overrides.Open(name)
+
+	if err == nil {
+		return file, nil
+	}
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return o.embedded.Open(name)
+	}
+
+	return nil, err
+}
+
+func UseTemplateOverrides(path string) error {
+	overrides := os.DirFS(path)
+	entries, err := fs.ReadDir(overrides, ".")
+
+	if err != nil {
+		return fmt.Errorf("could not read templates path: %v", err)
+	}
+
+	overridden := 0
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		if _, err := fs.Stat(TemplateFS, entry.Name()); err != nil {
+			slog.Warn("Ignoring file in templates path that doesn't match any built-in template", "file", entry.Name())
+			continue
+		}
+
+		overridden++
+	}
+
+	if err := compileTemplates(&templateOverridesFS{overrides: overrides, embedded: TemplateFS}); err != nil {
+		return fmt.Errorf("failed compiling templates with overrides: %v", err)
+	}
+
+	slog.Info("Using template overrides", "path", path, "count", overridden)
+
+	return nil
+}
 
-	return t
+// Used for templates defined by the user in their config, these have access to
+// the same functions as the built-in templates
+func CompileUserTemplate(name string, text string) (*template.Template, error) {
+	return template.New(name).Funcs(globalTemplateFunctions).Parse(text)
 }
 
 var intl = message.NewPrinter(language.English)
@@ -86,6 +221,19 @@ func formatViewerCount(count int) string {
 	return fmt.Sprintf("%.1fm", float64(count)/1_000_000)
 }
 
+// Formats durations the way media players do, such as 4:05 or 1:02:03
+func formatDuration(d time.Duration) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	hours, minutes := seconds/3600, seconds%3600/60
+	seconds %= 60
+
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	}
+
+	return fmt.Sprintf("%d:%02d", minutes, seconds)
+}
+
 func relativeTimeSince(t time.Time) string {
 	delta := time.Since(t)
 
@@ -0,0 +1,117 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/glanceapp/glance/internal/parser"
+)
+
+const (
+	articleCacheDuration    = 6 * time.Hour
+	articleErrCacheDuration = 10 * time.Minute
+	articleCacheMaxEntries  = 200
+)
+
+type cachedArticle struct {
+	article   *parser.Article
+	err       error
+	expiresAt time.Time
+}
+
+var articleCache = struct {
+	entries map[string]cachedArticle
+	mu      sync.Mutex
+}{entries: make(map[string]cachedArticle)}
+
+func cacheArticle(link string, article *parser.Article, err error) {
+	articleCache.mu.Lock()
+	defer articleCache.mu.Unlock()
+
+	now := time.Now()
+
+	if len(articleCache.entries) >= articleCacheMaxEntries {
+		for key, entry := range articleCache.entries {
+			if now.After(entry.expiresAt) {
+				delete(articleCache.entries, key)
+			}
+		}
+	}
+
+	// still full after removing the expired entries, drop an arbitrary one
+	if len(articleCache.entries) >= articleCacheMaxEntries {
+		for key := range articleCache.entries {
+			delete(articleCache.entries, key)
+			break
+		}
+	}
+
+	duration := articleCacheDuration
+
+	if err != nil {
+		duration = articleErrCacheDuration
+	}
+
+	articleCache.entries[link] = cachedArticle{article: article, err: err, expiresAt: now.Add(duration)}
+}
+
+func fetchArticle(ctx context.Context, link string, renderer parser.Renderer) (*parser.Article, error) {
+	parsed, err := url.Parse(link)
+
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("invalid article link %s", link)
+	}
+
+	page, err := renderer.Render(ctx, link)
+
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := parser.ParseHTMLs(page.HTML)
+
+	if err != nil {
+		return nil, err
+	}
+
+	article, err := parser.ExtractArticle(doc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the final URL after redirects is what relative links are relative to
+	base, err := url.Parse(page.URL)
+
+	if err != nil {
+		base = parsed
+	}
+
+	article.Content = string(SanitizeHTML(article.Content, base))
+
+	return article, nil
+}
+
+// Fetches the page the link points to and extracts its main content,
+// results are cached including failures to avoid hammering broken pages
+func FetchArticle(ctx context.Context, link string, renderer parser.Renderer) (*parser.Article, error) {
+	articleCache.mu.Lock()
+	entry, exists := articleCache.entries[link]
+	articleCache.mu.Unlock()
+
+	if exists && time.Now().Before(entry.expiresAt) {
+		return entry.article, entry.err
+	}
+
+	article, err := fetchArticle(ctx, link, renderer)
+
+	// failing because the request was cancelled says nothing about the page
+	if ctx.Err() == nil {
+		cacheArticle(link, article, err)
+	}
+
+	return article, err
+}
@@ -2,334 +2,345 @@
 package feed
 
 import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/glanceapp/glance/internal/define"
-	"github.com/glanceapp/glance/internal/parser"
-	"github.com/glanceapp/glance/internal/tool"
-	"io"
+	"html"
 	"log/slog"
 	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
-	"regexp"
+	"path"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
-// ParseTimeString 将时间字符串解析为 time.Time
-// 支持两种格式： "YYYY-M-D" 和 "M-D"
-// 如果是 "M-D" 格式，自动使用当前年份
-func ParseTimeString(timeStr string) (time.Time, error) {
-	timeStr = strings.TrimSpace(timeStr)
+const (
+	bilibiliAPIBase = "https://api.bilibili.com"
+	// WBI 密钥每天更换一次，提前刷新以免签名失效
+	bilibiliSessionDuration  = 6 * time.Hour
+	bilibiliVideosPerChannel = 15
+)
 
-	// 正则表达式匹配完整日期 "YYYY-M-D"
-	fullDateRegex := regexp.MustCompile(`^\d{4}-\d{1,2}-\d{1,2}$`)
-	// 正则表达式匹配简化日期 "M-D"
-	shortDateRegex := regexp.MustCompile(`^\d{1,2}-\d{1,2}$`)
+// 重新排列 img_key 与 sub_key 以得到签名用的 mixin key
+var bilibiliMixinKeyEncTab = []int{
+	46, 47, 18, 2, 53, 8, 23, 32, 15, 50, 10, 31, 58, 3, 45, 35, 27, 43, 5, 49,
+	33, 9, 42, 19, 29, 28, 14, 39, 12, 38, 41, 13, 37, 48, 7, 16, 24, 55, 40,
+	61, 26, 17, 0, 1, 60, 51, 30, 4, 22, 25, 54, 21, 56, 59, 6, 63, 57, 62, 11,
+	36, 20, 34, 44, 52,
+}
 
-	currentYear := time.Now().Year()
+// 签名前需要从参数值中移除的字符
+var bilibiliWbiValueReplacer = strings.NewReplacer("!", "", "'", "", "(", "", ")", "", "*", "")
 
-	if fullDateRegex.MatchString(timeStr) {
-		// 尝试使用 "2006-1-2" 格式解析
-		parsedTime, err := time.Parse("2006-1-2", timeStr)
-		if err != nil {
-			return time.Time{}, fmt.Errorf("无法解析完整日期格式: %w", err)
-		}
-		return parsedTime, nil
-	} else if shortDateRegex.MatchString(timeStr) {
-		// 拼接当前年份，形成 "YYYY-M-D"
-		fullDateStr := fmt.Sprintf("%d-%s", currentYear, timeStr)
-		parsedTime, err := time.Parse("2006-1-2", fullDateStr)
-		if err != nil {
-			return time.Time{}, fmt.Errorf("无法解析简化日期格式: %w", err)
-		}
+type bilibiliNavResponseJson struct {
+	Data struct {
+		WbiImg struct {
+			ImgURL string `json:"img_url"`
+			SubURL string `json:"sub_url"`
+		} `json:"wbi_img"`
+	} `json:"data"`
+}
 
-		// 如果解析的日期在未来，假设它属于上一年
-		if parsedTime.After(time.Now()) {
-			fullDateStr = fmt.Sprintf("%d-%s", currentYear-1, timeStr)
-			parsedTime, err = time.Parse("2006-1-2", fullDateStr)
-			if err != nil {
-				return time.Time{}, fmt.Errorf("无法解析简化日期格式为上一年: %w", err)
-			}
-		}
+type bilibiliSpiResponseJson struct {
+	Code int `json:"code"`
+	Data struct {
+		Buvid3 string `json:"b_3"`
+		Buvid4 string `json:"b_4"`
+	} `json:"data"`
+}
 
-		return parsedTime, nil
-	} else {
-		return time.Time{}, errors.New("不支持的日期格式")
+// 播放量在视频被限制时为 "--" 之类的字符串
+type bilibiliCount int
+
+func (c *bilibiliCount) UnmarshalJSON(data []byte) error {
+	var number int
+
+	if err := json.Unmarshal(data, &number); err == nil {
+		*c = bilibiliCount(number)
+		return nil
 	}
+
+	*c = 0
+
+	return nil
 }
 
-func FetchBilibiliChannelUploads(channelIds []string, videoUrlTemplate string, includeShorts bool) (Videos, error) {
-	var (
-		videos     Videos
-		fetchError error
-	)
-
-	// 定义 XPath 表达式
-	cardBoxXPath := "//div[contains(@class, 'content') and contains(@class, 'clearfix')]/div"
-	hrefXPath := ".//a[contains(@class, 'cover')]"
-	imgXPath := ".//div[contains(@class, 'b-img')]//source[@type='image/webp']"
-	titleXPath := ".//a[contains(@class, 'title')]/text()"
-	timeXPath := ".//span[contains(@class, 'time')]/text()"
-	authorPath := ".//span[@id=\"h-name\"]/text()"
-
-	// 顺序处理每个频道
-	for _, channelId := range channelIds {
-		channelURL := fmt.Sprintf("https://space.bilibili.com/%s", channelId)
-		channelContent := fmt.Sprintf("html/bilibili_%s.html", channelId)
-
-		// 获取当前可执行文件的路径
-		exePath, err := os.Executable()
-		if err != nil {
-			fmt.Println("Error getting executable path:", err)
-		}
-		// 获取当前可执行文件的目录
-		exeDir := filepath.Dir(exePath)
-		// 拼接文件路径
-		bilibiliCookiePath := filepath.Join(exeDir, "cookie", "bilibili_cookie.json")
-		// 创建cookie目录
-		if err := os.MkdirAll(filepath.Dir(bilibiliCookiePath), os.ModePerm); err != nil {
-			slog.Error("创建cookie目录失败: %v", err)
-		}
+type bilibiliArchiveResponseJson struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		List struct {
+			Vlist []struct {
+				Bvid    string        `json:"bvid"`
+				Title   string        `json:"title"`
+				Pic     string        `json:"pic"`
+				Author  string        `json:"author"`
+				Created int64         `json:"created"`
+				Length  string        `json:"length"`
+				Play    bilibiliCount `json:"play"`
+			} `json:"vlist"`
+		} `json:"list"`
+	} `json:"data"`
+}
 
-		helper := tool.NewDynamicKeyHelper()
-		// 生成密钥
-		key, err := helper.GenerateKey(define.SharedSecret, define.DefaultTimeStep)
-		if err != nil {
-			slog.Error("生成密钥时出错: %v", err)
-		}
+type bilibiliAPIClient struct {
+	client  RequestDoer
+	apiBase string
 
-		params := url.Values{}
-		params.Add("name", "bilibili")
-		params.Add("key", key)
+	mu               sync.Mutex
+	mixinKey         string
+	cookie           string
+	sessionExpiresAt time.Time
+}
 
-		fullURL := fmt.Sprintf("%s?%s", define.CookieAPI, params.Encode())
+var bilibiliClient = &bilibiliAPIClient{
+	client:  defaultClient,
+	apiBase: bilibiliAPIBase,
+}
 
-		// 发送GET请求获取Cookie JSON
-		resp, err := http.Get(fullURL)
-		if err != nil {
-			slog.Error("GET请求失败: %v", err)
-		}
-		defer resp.Body.Close()
+func newBilibiliRequest(requestUrl string, cookie string) *http.Request {
+	request, _ := http.NewRequest("GET", requestUrl, nil)
+	addBrowserUserAgentHeader(request)
+	request.Header.Set("Referer", "https://space.bilibili.com/")
+	request.Header.Set("Origin", "https://space.bilibili.com")
 
-		// 保存响应体到文件
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			slog.Error("读取响应体失败: %v", err)
-		}
+	if cookie != "" {
+		request.Header.Set("Cookie", cookie)
+	}
 
-		if err := os.WriteFile(bilibiliCookiePath, body, 0644); err != nil {
-			slog.Error("写入文件失败: %v", err)
-		}
+	return request
+}
 
-		fetchContentExePath := filepath.Join(exeDir, "fetch_web", "fetch_content.exe")
-
-		// 定义调用参数
-		options := parser.FetchOptions{
-			URL:         channelURL,
-			Timeout:     15,
-			Show:        true, // 根据需要设置
-			Wait:        "load",
-			Render:      5000,
-			OutputDir:   channelContent,
-			UserDataDir: "./user_data",
-			CookieFile:  bilibiliCookiePath,
-			FetcherPath: fetchContentExePath,
-		}
+// 从图片链接中取出文件名作为密钥，例如 .../7cd084941338484aae1ad9425b84077c.png
+func bilibiliWbiKeyFromURL(imageUrl string) string {
+	name := path.Base(imageUrl)
+	return strings.TrimSuffix(name, path.Ext(name))
+}
 
-		// 调用 FetchHTML 函数获取 HTML 内容
-		htmlContent, err := parser.FetchHTML(options)
-		if err != nil {
-			slog.Error("Failed to fetch HTML", "channel", channelId, "error", err)
-			if fetchError == nil {
-				fetchError = err
-			}
-			continue
-		}
+func bilibiliMixinKey(imgKey, subKey string) string {
+	raw := imgKey + subKey
+	var builder strings.Builder
 
-		// 解析 HTML 内容
-		doc, err := parser.ParseHTMLs(htmlContent)
-		if err != nil {
-			slog.Error("Failed to parse HTML", "channel", channelId, "error", err)
-			if fetchError == nil {
-				fetchError = err
-			}
-			continue
+	for _, index := range bilibiliMixinKeyEncTab {
+		if index < len(raw) {
+			builder.WriteByte(raw[index])
 		}
+	}
 
-		// 提取作者信息
-		author, err := parser.GetText(doc, authorPath)
-		if err != nil {
-			slog.Error("未能提取 author", "channel", channelId, "error", err)
-			author = "None"
-		} else {
-			author = strings.TrimSpace(author)
-		}
+	key := builder.String()
 
-		// 查找所有 cardBox 节点
-		cardBoxes, err := parser.FindNodes(doc, cardBoxXPath)
-		if err != nil {
-			slog.Error("Failed to find card boxes", "channel", channelId, "error", err)
-			if fetchError == nil {
-				fetchError = err
-			}
-			continue
-		}
+	if len(key) > 32 {
+		key = key[:32]
+	}
 
-		var channelVideos Videos
-
-		for _, cardBox := range cardBoxes {
-			// 提取 <a class='cover'> 节点并获取 href 属性
-			aNode, err := parser.FindNode(cardBox, hrefXPath)
-			if err != nil || aNode == nil {
-				slog.Error("未找到 a.cover 节点", "channel", channelId, "error", err)
-				continue
-			}
-			href, err := parser.GetAttribute(aNode, "href")
-			if err != nil {
-				slog.Error("未能提取 href 属性", "channel", channelId, "error", err)
-				href = ""
-			} else {
-				href = strings.Replace(href, "//", "", 1)
-			}
-
-			// 提取 <source type='image/webp'> 节点并获取 srcset 属性
-			sourceNode, err := parser.FindNode(cardBox, imgXPath)
-			if err != nil || sourceNode == nil {
-				slog.Error("未找到 source[type='image/webp'] 节点", "channel", channelId, "error", err)
-				continue
-			}
-			imgSrc, err := parser.GetAttribute(sourceNode, "srcset")
-			if err != nil {
-				slog.Error("未能提取 srcset 属性", "channel", channelId, "error", err)
-				imgSrc = ""
-			} else {
-				imgSrc = "https:" + imgSrc
-			}
-
-			// 提取 title
-			title, err := parser.GetText(cardBox, titleXPath)
-			if err != nil {
-				slog.Error("未能提取 title", "channel", channelId, "error", err)
-				title = ""
-			} else {
-				title = strings.TrimSpace(title)
-			}
-
-			// 提取 time
-			timeStr, err := parser.GetText(cardBox, timeXPath)
-			if err != nil {
-				slog.Error("未能提取 time", "channel", channelId, "error", err)
-				timeStr = ""
-			} else {
-				timeStr = strings.TrimSpace(timeStr)
-			}
-
-			// 解析发布时间
-			parsedTime, err := ParseTimeString(timeStr)
-			if err != nil {
-				slog.Error("无法解析时间字符串", "channel", channelId, "time", timeStr, "error", err)
-				parsedTime = time.Time{}
-				continue
-			}
-
-			// 构造视频 URL
-			videoUrl := "https://" + href
-
-			// 构造 Author URL
-			authorUrl := fmt.Sprintf("https://space.bilibili.com/%s/video", channelId)
-
-			// 添加视频信息到频道视频列表
-			channelVideos = append(channelVideos, Video{
-				ThumbnailUrl: imgSrc,
-				Title:        title,
-				Url:          videoUrl,
-				Author:       author,
-				AuthorUrl:    authorUrl,
-				TimePosted:   parsedTime,
-			})
-		}
+	return key
+}
 
-		// 如果没有视频，跳过
-		if len(channelVideos) == 0 {
-			continue
+// 按照 WBI 的规则为参数签名，返回包含 wts 与 w_rid 的查询字符串
+func signBilibiliWbiParams(params url.Values, mixinKey string, now time.Time) string {
+	signed := make(url.Values, len(params)+2)
+
+	for key, values := range params {
+		for _, value := range values {
+			signed.Add(key, bilibiliWbiValueReplacer.Replace(value))
 		}
+	}
 
-		// 排序视频，按发布时间降序
-		sort.Slice(channelVideos, func(i, j int) bool {
-			return channelVideos[i].TimePosted.After(channelVideos[j].TimePosted)
-		})
+	signed.Set("wts", strconv.FormatInt(now.Unix(), 10))
 
-		// 仅保留前 5 个视频
-		if len(channelVideos) > 5 {
-			channelVideos = channelVideos[:5]
-		}
+	keys := make([]string, 0, len(signed))
 
-		// 将频道视频添加到总视频列表
-		videos = append(videos, channelVideos...)
+	for key := range signed {
+		keys = append(keys, key)
 	}
 
-	if fetchError != nil && len(videos) == 0 {
-		return nil, fetchError
-	}
+	sort.Strings(keys)
 
-	if len(videos) == 0 {
-		return nil, ErrNoContent
+	parts := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		// 与 encodeURIComponent 一致，空格编码为 %20 而不是 +
+		value := strings.ReplaceAll(url.QueryEscape(signed.Get(key)), "+", "%20")
+		parts = append(parts, url.QueryEscape(key)+"="+value)
 	}
 
-	return videos, nil
+	query := strings.Join(parts, "&")
+	hash := md5.Sum([]byte(query + mixinKey))
+
+	return query + "&w_rid=" + hex.EncodeToString(hash[:])
 }
 
-// ExtractedData 定义提取的数据结构
-type ExtractedData struct {
-	Href   string
-	ImgSrc string
-	Title  string
-	Time   string
-	Author string
+// 获取签名用的密钥以及匿名访问需要的 buvid cookie，结果会缓存一段时间
+func (c *bilibiliAPIClient) session() (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.mixinKey != "" && time.Now().Before(c.sessionExpiresAt) {
+		return c.mixinKey, c.cookie, nil
+	}
+
+	// 没有 buvid 时接口更容易触发风控，但获取失败时仍然可以尝试请求
+	var cookie string
+	spi, err := decodeJsonFromRequest[bilibiliSpiResponseJson](c.client, newBilibiliRequest(c.apiBase+"/x/frontend/finger/spi", ""))
+
+	if err != nil {
+		slog.Warn("Failed to get bilibili buvid", "error", err)
+	} else if spi.Code == 0 && spi.Data.Buvid3 != "" {
+		cookie = "buvid3=" + spi.Data.Buvid3 + "; buvid4=" + url.QueryEscape(spi.Data.Buvid4)
+	}
+
+	// 未登录时接口返回 -101，但依然包含 wbi_img
+	nav, err := decodeJsonFromRequest[bilibiliNavResponseJson](c.client, newBilibiliRequest(c.apiBase+"/x/web-interface/nav", cookie))
+
+	if err != nil {
+		return "", "", fmt.Errorf("getting WBI keys: %v", err)
+	}
+
+	imgKey := bilibiliWbiKeyFromURL(nav.Data.WbiImg.ImgURL)
+	subKey := bilibiliWbiKeyFromURL(nav.Data.WbiImg.SubURL)
+
+	if imgKey == "" || subKey == "" || imgKey == "." || subKey == "." {
+		return "", "", errors.New("getting WBI keys: response did not contain the keys")
+	}
+
+	c.mixinKey = bilibiliMixinKey(imgKey, subKey)
+	c.cookie = cookie
+	c.sessionExpiresAt = time.Now().Add(bilibiliSessionDuration)
+
+	return c.mixinKey, c.cookie, nil
 }
 
-// FetchResult 定义抓取结果的结构
-type FetchResult struct {
-	URL   string
-	Data  []ExtractedData
-	Error error
+func (c *bilibiliAPIClient) invalidateSession() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.mixinKey = ""
 }
 
-// extractVideoID 从 href 中提取视频 ID
-func extractVideoID(href string) string {
-	// 假设 href 格式为 "/video/BV1xxxxxx" 或类似格式，根据实际情况调整
-	parts := strings.Split(href, "/")
-	if len(parts) > 2 {
-		return parts[2]
+func parseBilibiliArchiveResponse(response *bilibiliArchiveResponseJson, mid string) (Videos, error) {
+	if response.Code != 0 {
+		return nil, fmt.Errorf("bilibili API returned code %d: %s", response.Code, response.Message)
 	}
-	return ""
-}
 
-// extractAuthorID 从 href 中提取作者 ID
-func extractAuthorID(href string) int64 {
-	// 假设 href 包含作者 ID，可以根据实际情况提取
-	// 这里返回一个固定值作为示例
-	return 19956596
+	vlist := response.Data.List.Vlist
+	videos := make(Videos, 0, len(vlist))
+
+	for i := range vlist {
+		video := &vlist[i]
+
+		duration := parseITunesDuration(video.Length)
+
+		// 封面链接可能是 http 或者省略了协议
+		thumbnailUrl := video.Pic
+
+		if strings.HasPrefix(thumbnailUrl, "//") {
+			thumbnailUrl = "https:" + thumbnailUrl
+		} else {
+			thumbnailUrl = strings.Replace(thumbnailUrl, "http://", "https://", 1)
+		}
+
+		videos = append(videos, Video{
+			ThumbnailUrl: thumbnailUrl,
+			Title:        html.UnescapeString(video.Title),
+			Url:          "https://www.bilibili.com/video/" + video.Bvid,
+			Author:       video.Author,
+			AuthorUrl:    fmt.Sprintf("https://space.bilibili.com/%s/video", mid),
+			TimePosted:   time.Unix(video.Created, 0),
+			Views:        int(video.Play),
+			Duration:     duration,
+			IsShort:      isShortDuration(duration),
+		})
+	}
+
+	return videos, nil
 }
 
-// addDomain 如果 URL 是相对路径，则添加域名
-func addDomain(base, link string) string {
-	parsedBase, err := url.Parse(base)
+func (c *bilibiliAPIClient) fetchChannelUploads(mid string) (Videos, error) {
+	mixinKey, cookie, err := c.session()
+
 	if err != nil {
-		return link
+		return nil, err
 	}
-	parsedLink, err := url.Parse(link)
+
+	params := url.Values{}
+	params.Set("mid", mid)
+	params.Set("ps", strconv.Itoa(bilibiliVideosPerChannel))
+	params.Set("pn", "1")
+	params.Set("order", "pubdate")
+	params.Set("platform", "web")
+	// 浏览器会附带的 WebGL 信息，缺少时接口经常返回 -352
+	params.Set("dm_img_list", "[]")
+	params.Set("dm_img_str", "V2ViR0wgMS4wIChPcGVuR0wgRVMgMi4wIENocm9taXVtKQ")
+	params.Set("dm_cover_img_str", "QU5HTEUgKEludGVsLCBJbnRlbChSKSBVSEQgR3JhcGhpY3MgNjMwICgweDAwMDAzRTlCKSBEaXJlY3QzRDExIHZzXzVfMCBwc181XzAsIEQzRDExKUdvb2dsZSBJbmMuIChJbnRlbC")
+
+	request := newBilibiliRequest(c.apiBase+"/x/space/wbi/arc/search?"+signBilibiliWbiParams(params, mixinKey, time.Now()), cookie)
+	request.Header.Set("Referer", "https://space.bilibili.com/"+mid+"/video")
+
+	response, err := decodeJsonFromRequest[bilibiliArchiveResponseJson](c.client, request)
+
 	if err != nil {
-		return link
+		return nil, err
+	}
+
+	// 签名失效或者触发风控，下次更新时重新获取密钥与 cookie
+	if response.Code == -352 || response.Code == -403 || response.Code == -412 {
+		c.invalidateSession()
 	}
-	return parsedBase.ResolveReference(parsedLink).String()
+
+	return parseBilibiliArchiveResponse(&response, mid)
+}
+
+func FetchBilibiliChannelUploads(channelIds []string) (Videos, error) {
+	// 并发过高容易触发风控
+	job := newJob(bilibiliClient.fetchChannelUploads, channelIds).withWorkers(2)
+	responses, errs, err := workerPoolDo(job)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoContent, err)
+	}
+
+	videos := make(Videos, 0, len(channelIds)*bilibiliVideosPerChannel)
+	var failed int
+
+	for i := range responses {
+		if errs[i] != nil {
+			failed++
+			slog.Error("Failed to fetch bilibili channel", "channel", channelIds[i], "error", errs[i])
+			continue
+		}
+
+		videos = append(videos, responses[i]...)
+	}
+
+	if len(videos) == 0 {
+		return nil, ErrNoContent
+	}
+
+	videos.SortByNewest()
+
+	if failed > 0 {
+		return videos, fmt.Errorf("%w: missing videos from %d channels", ErrPartialContent, failed)
+	}
+
+	return videos, nil
+}
+
+type bilibiliVideoSource struct{}
+
+func (bilibiliVideoSource) ParseChannel(channel string) (string, error) {
+	if !bilibiliChannelPattern.MatchString(channel) {
+		return "", errors.New("user ID must be made up of digits only")
+	}
+
+	return channel, nil
 }
 
-// parseBilibiliPubDate 将发布时间字符串转换为 time.Time
-func parseBilibiliPubDate(t time.Time) time.Time {
-	return t
+// 链接模板用于替换 YouTube 的前端，不适用于 B 站视频
+func (bilibiliVideoSource) FetchChannelUploads(channels []string, options VideoFetchOptions) (Videos, error) {
+	return FetchBilibiliChannelUploads(channels)
 }
@@ -0,0 +1,140 @@
+package feed
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readBilibiliFixture(t *testing.T, name string) []byte {
+	t.Helper()
+
+	contents, err := os.ReadFile(filepath.Join("testdata", name))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return contents
+}
+
+// 使用 WBI 文档中的示例密钥与参数
+func TestSignBilibiliWbiParams(t *testing.T) {
+	mixinKey := bilibiliMixinKey(
+		bilibiliWbiKeyFromURL("https://i0.hdslb.com/bfs/wbi/7cd084941338484aae1ad9425b84077c.png"),
+		bilibiliWbiKeyFromURL("https://i0.hdslb.com/bfs/wbi/4932caff0ff746eab6f01bf08b70ac45.png"),
+	)
+
+	if mixinKey != "ea1db124af3c7062474693fa704f4ff8" {
+		t.Fatalf("unexpected mixin key %q", mixinKey)
+	}
+
+	params := url.Values{}
+	params.Set("foo", "114")
+	params.Set("bar", "514")
+	params.Set("zab", "1919810")
+
+	query := signBilibiliWbiParams(params, mixinKey, time.Unix(1702204169, 0))
+	expected := "bar=514&foo=114&wts=1702204169&zab=1919810&w_rid=8f6f2b5b3d485fe1886cec6a0be8c5d4"
+
+	if query != expected {
+		t.Fatalf("expected %q, got %q", expected, query)
+	}
+}
+
+func TestBilibiliCountWithRestrictedPlays(t *testing.T) {
+	var counts []bilibiliCount
+
+	if err := json.Unmarshal([]byte(`[284317, "--", 0]`), &counts); err != nil {
+		t.Fatal(err)
+	}
+
+	if counts[0] != 284317 || counts[1] != 0 || counts[2] != 0 {
+		t.Fatalf("unexpected counts %v", counts)
+	}
+}
+
+func TestBilibiliFetchChannelUploads(t *testing.T) {
+	responses := map[string][]byte{
+		"/x/frontend/finger/spi":  readBilibiliFixture(t, "bilibili-spi.json"),
+		"/x/web-interface/nav":    readBilibiliFixture(t, "bilibili-nav.json"),
+		"/x/space/wbi/arc/search": readBilibiliFixture(t, "bilibili-arc-search.json"),
+	}
+
+	var searchQuery string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, exists := responses[r.URL.Path]
+
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+
+		if r.URL.Path == "/x/space/wbi/arc/search" {
+			searchQuery = r.URL.RawQuery
+
+			if !strings.Contains(r.Header.Get("Cookie"), "buvid3=") {
+				t.Error("search request is missing the buvid cookie")
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(response)
+	}))
+	defer server.Close()
+
+	client := &bilibiliAPIClient{client: server.Client(), apiBase: server.URL}
+	videos, err := client.fetchChannelUploads("12345678")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 签名应当覆盖 w_rid 之前的全部参数
+	signed, wRid, found := strings.Cut(searchQuery, "&w_rid=")
+
+	if !found {
+		t.Fatalf("search request is not signed: %s", searchQuery)
+	}
+
+	hash := md5.Sum([]byte(signed + "ea1db124af3c7062474693fa704f4ff8"))
+
+	if wRid != hex.EncodeToString(hash[:]) {
+		t.Fatalf("w_rid %s does not match the signed parameters", wRid)
+	}
+
+	if len(videos) != 2 {
+		t.Fatalf("expected 2 videos, got %d", len(videos))
+	}
+
+	first, second := videos[0], videos[1]
+
+	if first.Title != "Go 1.23 新特性：range over func & 迭代器" {
+		t.Errorf("title was not unescaped: %q", first.Title)
+	}
+
+	if first.Views != 284317 || first.Duration != 12*time.Minute+34*time.Second || first.IsShort {
+		t.Errorf("unexpected first video %+v", first)
+	}
+
+	if !strings.HasPrefix(first.ThumbnailUrl, "https://") || !strings.HasPrefix(second.ThumbnailUrl, "https://") {
+		t.Errorf("thumbnails should use https: %q, %q", first.ThumbnailUrl, second.ThumbnailUrl)
+	}
+
+	if second.Views != 0 || !second.IsShort {
+		t.Errorf("unexpected second video %+v", second)
+	}
+
+	if second.Url != "https://www.bilibili.com/video/BV1yy411c7nE" || second.AuthorUrl != "https://space.bilibili.com/12345678/video" {
+		t.Errorf("unexpected links %q, %q", second.Url, second.AuthorUrl)
+	}
+}
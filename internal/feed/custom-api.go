@@ -0,0 +1,392 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CustomAPIRequest struct {
+	URL           string
+	Method        string
+	Headers       map[string]string
+	Body          string
+	ResponseType  string
+	AllowInsecure bool
+}
+
+type CustomAPIResponse struct {
+	StatusCode int
+	Headers    http.Header
+	Data       DecodedValue
+}
+
+// A value decoded from a JSON or XML response, the path methods accept
+// dot separated keys and array indexes such as `items.0.title`, `items[0].title`
+// or `$.items[*].title` where `*` matches every element of an array
+type DecodedValue struct {
+	value any
+}
+
+func NewDecodedValue(value any) DecodedValue {
+	return DecodedValue{value: value}
+}
+
+func splitDecodedValuePath(path string) []string {
+	path = strings.TrimPrefix(path, "$")
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+
+	parts := strings.Split(path, ".")
+	keys := make([]string, 0, len(parts))
+
+	for i := range parts {
+		if parts[i] != "" {
+			keys = append(keys, parts[i])
+		}
+	}
+
+	return keys
+}
+
+func lookupDecodedValue(value any, keys []string) (any, bool) {
+	if len(keys) == 0 {
+		return value, true
+	}
+
+	key := keys[0]
+
+	switch v := value.(type) {
+	case map[string]any:
+		child, ok := v[key]
+
+		if !ok {
+			return nil, false
+		}
+
+		return lookupDecodedValue(child, keys[1:])
+	case []any:
+		if key == "*" {
+			matches := make([]any, 0, len(v))
+
+			for i := range v {
+				if match, ok := lookupDecodedValue(v[i], keys[1:]); ok {
+					matches = append(matches, match)
+				}
+			}
+
+			return matches, true
+		}
+
+		index, err := strconv.Atoi(key)
+
+		if err != nil {
+			return nil, false
+		}
+
+		if index < 0 {
+			index += len(v)
+		}
+
+		if index < 0 || index >= len(v) {
+			return nil, false
+		}
+
+		return lookupDecodedValue(v[index], keys[1:])
+	}
+
+	return nil, false
+}
+
+func (v DecodedValue) Get(path string) DecodedValue {
+	value, _ := lookupDecodedValue(v.value, splitDecodedValuePath(path))
+
+	return DecodedValue{value: value}
+}
+
+func (v DecodedValue) Exists(path string) bool {
+	_, ok := lookupDecodedValue(v.value, splitDecodedValuePath(path))
+
+	return ok
+}
+
+func (v DecodedValue) Raw() any {
+	return v.value
+}
+
+func (v DecodedValue) String(path string) string {
+	switch value := v.Get(path).value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	default:
+		encoded, err := json.Marshal(value)
+
+		if err != nil {
+			return ""
+		}
+
+		return string(encoded)
+	}
+}
+
+func (v DecodedValue) Float(path string) float64 {
+	switch value := v.Get(path).value.(type) {
+	case float64:
+		return value
+	case string:
+		parsed, _ := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return parsed
+	case bool:
+		if value {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func (v DecodedValue) Int(path string) int {
+	return int(v.Float(path))
+}
+
+func (v DecodedValue) Bool(path string) bool {
+	switch value := v.Get(path).value.(type) {
+	case bool:
+		return value
+	case float64:
+		return value != 0
+	case string:
+		parsed, _ := strconv.ParseBool(strings.TrimSpace(value))
+		return parsed
+	}
+
+	return false
+}
+
+// Parses the value at the path as a unix timestamp in seconds or a string in
+// any of the formats supported by ParseTime, times without a zone are in UTC
+func (v DecodedValue) Time(path string) time.Time {
+	switch value := v.Get(path).value.(type) {
+	case float64:
+		return time.Unix(int64(value), 0)
+	case string:
+		if parsed, err := ParseTime(value, nil); err == nil {
+			return parsed
+		}
+
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return time.Unix(parsed, 0)
+		}
+	}
+
+	return time.Time{}
+}
+
+func (v DecodedValue) Array(path string) []DecodedValue {
+	var items []any
+
+	switch value := v.Get(path).value.(type) {
+	case []any:
+		items = value
+	case nil:
+		return nil
+	default:
+		// XML elements which only appear once are not wrapped in an array
+		items = []any{value}
+	}
+
+	values := make([]DecodedValue, len(items))
+
+	for i := range items {
+		values[i] = DecodedValue{value: items[i]}
+	}
+
+	return values
+}
+
+// Converts an XML document into the same structure as a decoded JSON document,
+// attributes are prefixed with `@`, text content is stored under `#text` unless
+// the element has no attributes or children in which case it becomes a string
+func decodeXMLAsValue(body []byte) (any, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false
+
+	type element struct {
+		name     string
+		children map[string]any
+		text     strings.Builder
+	}
+
+	root := &element{children: make(map[string]any)}
+	stack := []*element{root}
+
+	appendChild := func(parent *element, name string, value any) {
+		existing, ok := parent.children[name]
+
+		if !ok {
+			parent.children[name] = value
+			return
+		}
+
+		if list, ok := existing.([]any); ok {
+			parent.children[name] = append(list, value)
+			return
+		}
+
+		parent.children[name] = []any{existing, value}
+	}
+
+	for {
+		token, err := decoder.Token()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			el := &element{name: t.Name.Local, children: make(map[string]any)}
+
+			for _, attr := range t.Attr {
+				el.children["@"+attr.Name.Local] = attr.Value
+			}
+
+			stack = append(stack, el)
+		case xml.CharData:
+			stack[len(stack)-1].text.Write(t)
+		case xml.EndElement:
+			if len(stack) < 2 {
+				return nil, errors.New("unexpected closing element")
+			}
+
+			el := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			text := strings.TrimSpace(el.text.String())
+
+			if len(el.children) == 0 {
+				appendChild(stack[len(stack)-1], el.name, text)
+				continue
+			}
+
+			if text != "" {
+				el.children["#text"] = text
+			}
+
+			appendChild(stack[len(stack)-1], el.name, el.children)
+		}
+	}
+
+	return root.children, nil
+}
+
+func decodeCustomAPIBody(body []byte, responseType string, contentType string) (any, error) {
+	if responseType == "" || responseType == "auto" {
+		if strings.Contains(contentType, "xml") {
+			responseType = "xml"
+		} else {
+			responseType = "json"
+		}
+	}
+
+	switch responseType {
+	case "json":
+		var value any
+
+		if err := json.Unmarshal(body, &value); err != nil {
+			return nil, fmt.Errorf("could not decode JSON: %v", err)
+		}
+
+		return value, nil
+	case "xml":
+		value, err := decodeXMLAsValue(body)
+
+		if err != nil {
+			return nil, fmt.Errorf("could not decode XML: %v", err)
+		}
+
+		return value, nil
+	case "text":
+		return string(body), nil
+	}
+
+	return nil, fmt.Errorf("unknown response type %s", responseType)
+}
+
+func FetchCustomAPI(req CustomAPIRequest) (*CustomAPIResponse, error) {
+	var body io.Reader
+
+	if req.Body != "" {
+		body = strings.NewReader(req.Body)
+	}
+
+	request, err := http.NewRequest(req.Method, req.URL, body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range req.Headers {
+		request.Header.Set(key, value)
+	}
+
+	var client RequestDoer = defaultClient
+
+	if req.AllowInsecure {
+		client = defaultInsecureClient
+	}
+
+	response, err := client.Do(request)
+
+	if err != nil {
+		slog.Error("failed fetching custom api", "error", err, "url", req.URL)
+		return nil, fmt.Errorf("%w: request failed: %w", ErrNoContent, err)
+	}
+
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not read body: %w", ErrNoContent, err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf(
+			"%w: unexpected status code %d for %s, response: %s",
+			ErrNoContent,
+			response.StatusCode,
+			req.URL,
+			truncateString(string(responseBody), 256),
+		)
+	}
+
+	data, err := decodeCustomAPIBody(responseBody, req.ResponseType, response.Header.Get("Content-Type"))
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoContent, err)
+	}
+
+	return &CustomAPIResponse{
+		StatusCode: response.StatusCode,
+		Headers:    response.Header,
+		Data:       DecodedValue{value: data},
+	}, nil
+}
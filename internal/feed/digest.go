@@ -0,0 +1,143 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glanceapp/glance/internal/tool"
+)
+
+const digestSummaryWorkers = 2
+
+type DigestItem struct {
+	Title       string
+	URL         string
+	Description string
+	PublishedAt time.Time
+}
+
+// The items of a single widget, summarized together
+type DigestSource struct {
+	Name  string
+	Items []DigestItem
+}
+
+type DigestSection struct {
+	Name    string
+	Summary string
+	Items   []DigestItem
+}
+
+type Digest struct {
+	GeneratedAt time.Time
+	Sections    []DigestSection
+}
+
+func (i RSSFeedItems) DigestItems() []DigestItem {
+	items := make([]DigestItem, len(i))
+
+	for j := range i {
+		items[j] = DigestItem{
+			Title:       i[j].Title,
+			URL:         i[j].Link,
+			Description: i[j].Description,
+			PublishedAt: i[j].PublishedAt,
+		}
+	}
+
+	return items
+}
+
+func (p ForumPosts) DigestItems() []DigestItem {
+	items := make([]DigestItem, len(p))
+
+	for i := range p {
+		items[i] = DigestItem{
+			Title:       p[i].Title,
+			URL:         p[i].DiscussionUrl,
+			PublishedAt: p[i].TimePosted,
+		}
+	}
+
+	return items
+}
+
+func (r AppReleases) DigestItems() []DigestItem {
+	items := make([]DigestItem, len(r))
+
+	for i := range r {
+		items[i] = DigestItem{
+			Title:       r[i].Name + " " + r[i].Version,
+			URL:         r[i].NotesUrl,
+			PublishedAt: r[i].TimeReleased,
+		}
+	}
+
+	return items
+}
+
+func digestPrompt(source DigestSource) string {
+	var builder strings.Builder
+
+	builder.WriteString("Today's items from " + source.Name + ":\n\n")
+
+	for i := range source.Items {
+		builder.WriteString(strconv.Itoa(i+1) + ". " + source.Items[i].Title + "\n")
+
+		if source.Items[i].Description != "" {
+			builder.WriteString("   " + source.Items[i].Description + "\n")
+		}
+	}
+
+	return builder.String()
+}
+
+// Summarizes the items of each source separately so that the briefing can be
+// grouped the same way as the widgets, sections whose summary failed are
+// still included with just their items
+func GenerateDigest(ctx context.Context, summarizer tool.Summarizer, sources []DigestSource, language string) (*Digest, error) {
+	task := func(source DigestSource) (string, error) {
+		return summarizer.Summarize(ctx, digestPrompt(source), language)
+	}
+
+	job := newJob(task, sources).withWorkers(digestSummaryWorkers)
+	summaries, errs, err := workerPoolDo(job)
+
+	if err != nil {
+		return nil, err
+	}
+
+	digest := &Digest{
+		GeneratedAt: time.Now(),
+		Sections:    make([]DigestSection, len(sources)),
+	}
+
+	failed := 0
+
+	for i := range sources {
+		digest.Sections[i] = DigestSection{
+			Name:    sources[i].Name,
+			Summary: summaries[i],
+			Items:   sources[i].Items,
+		}
+
+		if errs[i] != nil {
+			slog.Error("Failed to summarize digest section", "section", sources[i].Name, "error", errs[i])
+			failed++
+		}
+	}
+
+	if failed == len(sources) {
+		return nil, fmt.Errorf("%w: could not summarize any of the sections, last error: %v", ErrNoContent, errs[len(errs)-1])
+	}
+
+	if failed > 0 {
+		return digest, fmt.Errorf("%w: could not summarize %d of %d sections", ErrPartialContent, failed, len(sources))
+	}
+
+	return digest, nil
+}
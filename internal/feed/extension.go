@@ -30,6 +30,7 @@ type ExtensionRequestOptions struct {
 	URL        string            `yaml:"url"`
 	Parameters map[string]string `yaml:"parameters"`
 	AllowHtml  bool              `yaml:"allow-potentially-dangerous-html"`
+	BasicHtml  bool              `yaml:"allow-basic-html"`
 }
 
 type Extension struct {
@@ -44,6 +45,12 @@ func convertExtensionContent(options ExtensionRequestOptions, content []byte, co
 			return template.HTML(content)
 		}
 
+		if options.BasicHtml {
+			base, _ := url.Parse(options.URL)
+
+			return SanitizeHTML(string(content), base)
+		}
+
 		fallthrough
 	default:
 		return template.HTML(html.EscapeString(string(content)))
@@ -0,0 +1,246 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	hackerNewsThreadCacheDuration   = 5 * time.Minute
+	hackerNewsThreadCacheMaxEntries = 50
+)
+
+var hackerNewsCommentBaseUrl, _ = url.Parse("https://news.ycombinator.com/")
+
+type hackerNewsItemResponseJson struct {
+	Id         int    `json:"id"`
+	Author     string `json:"by"`
+	Text       string `json:"text"`
+	TimePosted int64  `json:"time"`
+	Kids       []int  `json:"kids"`
+	Deleted    bool   `json:"deleted"`
+	Dead       bool   `json:"dead"`
+}
+
+type HackerNewsComment struct {
+	ID         int
+	Author     string
+	Text       template.HTML
+	TimePosted time.Time
+	Replies    []*HackerNewsComment
+	// replies that exist but weren't fetched because of the depth or count limits
+	MoreReplies int
+}
+
+// The number of replies hidden when the comment is collapsed
+func (c *HackerNewsComment) DescendantCount() int {
+	count := c.MoreReplies
+
+	for _, reply := range c.Replies {
+		count += 1 + reply.DescendantCount()
+	}
+
+	return count
+}
+
+type HackerNewsThread struct {
+	PostID   int
+	Comments []*HackerNewsComment
+	// top level comments that weren't fetched because of the count limit
+	MoreComments int
+	FetchedCount int
+}
+
+type HackerNewsThreadOptions struct {
+	MaxDepth    int
+	MaxComments int
+}
+
+type cachedHackerNewsThread struct {
+	thread    *HackerNewsThread
+	expiresAt time.Time
+}
+
+var hackerNewsThreadCache = struct {
+	entries map[string]cachedHackerNewsThread
+	mu      sync.Mutex
+}{entries: make(map[string]cachedHackerNewsThread)}
+
+func cacheHackerNewsThread(key string, thread *HackerNewsThread) {
+	hackerNewsThreadCache.mu.Lock()
+	defer hackerNewsThreadCache.mu.Unlock()
+
+	now := time.Now()
+
+	if len(hackerNewsThreadCache.entries) >= hackerNewsThreadCacheMaxEntries {
+		for key, entry := range hackerNewsThreadCache.entries {
+			if now.After(entry.expiresAt) {
+				delete(hackerNewsThreadCache.entries, key)
+			}
+		}
+	}
+
+	if len(hackerNewsThreadCache.entries) >= hackerNewsThreadCacheMaxEntries {
+		for key := range hackerNewsThreadCache.entries {
+			delete(hackerNewsThreadCache.entries, key)
+			break
+		}
+	}
+
+	hackerNewsThreadCache.entries[key] = cachedHackerNewsThread{
+		thread:    thread,
+		expiresAt: now.Add(hackerNewsThreadCacheDuration),
+	}
+}
+
+func fetchHackerNewsItems(ctx context.Context, ids []int) ([]hackerNewsItemResponseJson, []error, error) {
+	requests := make([]*http.Request, len(ids))
+
+	for i, id := range ids {
+		request, _ := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://hacker-news.firebaseio.com/v0/item/%d.json", id), nil)
+		requests[i] = request
+	}
+
+	task := decodeJsonFromRequestTask[hackerNewsItemResponseJson](defaultClient)
+	job := newJob(task, requests).withWorkers(20)
+
+	return workerPoolDo(job)
+}
+
+type pendingHackerNewsComment struct {
+	id     int
+	parent *HackerNewsComment
+}
+
+// Fetches the thread one level at a time so that each level can be requested
+// concurrently, comments keep the order in which the API ranks them
+func fetchHackerNewsThread(ctx context.Context, postId int, options HackerNewsThreadOptions) (*HackerNewsThread, error) {
+	posts, errs, err := fetchHackerNewsItems(ctx, []int{postId})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if errs[0] != nil {
+		return nil, fmt.Errorf("fetching post %d: %v", postId, errs[0])
+	}
+
+	thread := &HackerNewsThread{PostID: postId}
+	pending := make([]pendingHackerNewsComment, len(posts[0].Kids))
+
+	for i, id := range posts[0].Kids {
+		pending[i] = pendingHackerNewsComment{id: id}
+	}
+
+	failed := 0
+
+	for depth := 1; len(pending) > 0; depth++ {
+		if remaining := options.MaxComments - thread.FetchedCount; len(pending) > remaining {
+			for _, skipped := range pending[remaining:] {
+				if skipped.parent == nil {
+					thread.MoreComments++
+				} else {
+					skipped.parent.MoreReplies++
+				}
+			}
+
+			pending = pending[:remaining]
+		}
+
+		ids := make([]int, len(pending))
+
+		for i := range pending {
+			ids[i] = pending[i].id
+		}
+
+		items, errs, err := fetchHackerNewsItems(ctx, ids)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		var next []pendingHackerNewsComment
+
+		for i := range items {
+			if errs[i] != nil {
+				slog.Error("Failed to fetch hacker news comment", "error", errs[i], "id", ids[i])
+				failed++
+				continue
+			}
+
+			item := &items[i]
+
+			// deleted comments with replies are kept so that the replies have a place to go
+			if item.Dead || (item.Deleted && len(item.Kids) == 0) {
+				continue
+			}
+
+			comment := &HackerNewsComment{
+				ID:         item.Id,
+				Author:     item.Author,
+				Text:       SanitizeHTML(item.Text, hackerNewsCommentBaseUrl),
+				TimePosted: time.Unix(item.TimePosted, 0),
+			}
+
+			thread.FetchedCount++
+
+			if parent := pending[i].parent; parent == nil {
+				thread.Comments = append(thread.Comments, comment)
+			} else {
+				parent.Replies = append(parent.Replies, comment)
+			}
+
+			if depth >= options.MaxDepth {
+				comment.MoreReplies = len(item.Kids)
+				continue
+			}
+
+			for _, id := range item.Kids {
+				next = append(next, pendingHackerNewsComment{id: id, parent: comment})
+			}
+		}
+
+		pending = next
+	}
+
+	if failed > 0 && thread.FetchedCount == 0 {
+		return nil, fmt.Errorf("%w: could not fetch any of the comments", ErrNoContent)
+	}
+
+	return thread, nil
+}
+
+// Fetches the comments of a post as a tree, limited in how deep it goes and
+// how many comments it fetches in total since every comment is a request
+func FetchHackerNewsThread(ctx context.Context, postId int, options HackerNewsThreadOptions) (*HackerNewsThread, error) {
+	key := strconv.Itoa(postId) + "-" + strconv.Itoa(options.MaxDepth) + "-" + strconv.Itoa(options.MaxComments)
+
+	hackerNewsThreadCache.mu.Lock()
+	entry, exists := hackerNewsThreadCache.entries[key]
+	hackerNewsThreadCache.mu.Unlock()
+
+	if exists && time.Now().Before(entry.expiresAt) {
+		return entry.thread, nil
+	}
+
+	thread, err := fetchHackerNewsThread(ctx, postId, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cacheHackerNewsThread(key, thread)
+
+	return thread, nil
+}
@@ -2,12 +2,10 @@ package feed
 
 import (
 	"fmt"
-	"github.com/glanceapp/glance/internal/tool"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 )
 
@@ -31,57 +29,6 @@ func getHackerNewsPostIds(sort string) ([]int, error) {
 	return response, nil
 }
 
-// translationResult holds the result of a translation task
-type translationResult struct {
-	Index           int
-	OriginalTitle   string
-	TranslatedTitle string
-	Err             error
-}
-
-// translateTitlesConcurrently 并发翻译多个标题
-func translateTitlesConcurrently(titles []string) []string {
-	var wg sync.WaitGroup
-	resultsChan := make(chan translationResult, len(titles))
-	concurrencyLimit := 2 // 最大并发数，根据实际情况调整
-
-	sem := make(chan struct{}, concurrencyLimit)
-
-	for i, title := range titles {
-		wg.Add(1)
-		go func(index int, text string) {
-			defer wg.Done()
-			sem <- struct{}{}        // 获取一个信号量
-			defer func() { <-sem }() // 释放信号量
-
-			translated, err := tool.PromptTranslate(text)
-			if err != nil {
-				slog.Error("Failed to tool title", "error", err, "title", text)
-				translated = text // 翻译失败，使用原始标题
-			}
-
-			resultsChan <- translationResult{
-				Index:           index,
-				OriginalTitle:   text,
-				TranslatedTitle: translated,
-				Err:             err,
-			}
-		}(i, title)
-	}
-
-	// 等待所有翻译完成
-	wg.Wait()
-	close(resultsChan)
-
-	// 收集结果
-	translatedTitles := make([]string, len(titles))
-	for res := range resultsChan {
-		translatedTitles[res.Index] = res.TranslatedTitle
-	}
-
-	return translatedTitles
-}
-
 func getHackerNewsPostsFromIds(postIds []int, commentsUrlTemplate string) (ForumPosts, error) {
 	requests := make([]*http.Request, len(postIds))
 
@@ -98,44 +45,31 @@ func getHackerNewsPostsFromIds(postIds []int, commentsUrlTemplate string) (Forum
 		return nil, err
 	}
 
-	// 收集所有需要翻译的标题
-	titles := make([]string, 0, len(postIds))
-	indexMap := make([]int, 0, len(postIds)) // 保存每个标题对应的原始索引
+	posts := make(ForumPosts, 0, len(postIds))
 
-	for i, res := range results {
+	for i := range results {
 		if errs[i] != nil {
 			slog.Error("Failed to fetch or parse hacker news post", "error", errs[i], "url", requests[i].URL)
 			continue
 		}
-		titles = append(titles, res.Title)
-		indexMap = append(indexMap, i)
-	}
-
-	// 并发翻译标题
-	translatedTitles := translateTitlesConcurrently(titles)
-
-	// 构建 ForumPosts
-	posts := make(ForumPosts, 0, len(postIds))
-
-	for i, translatedTitle := range translatedTitles {
-		originalIndex := indexMap[i]
-		res := results[originalIndex]
 
 		var commentsUrl string
+
 		if commentsUrlTemplate == "" {
-			commentsUrl = "https://news.ycombinator.com/item?id=" + strconv.Itoa(res.Id)
+			commentsUrl = "https://news.ycombinator.com/item?id=" + strconv.Itoa(results[i].Id)
 		} else {
-			commentsUrl = strings.ReplaceAll(commentsUrlTemplate, "{POST-ID}", strconv.Itoa(res.Id))
+			commentsUrl = strings.ReplaceAll(commentsUrlTemplate, "{POST-ID}", strconv.Itoa(results[i].Id))
 		}
 
 		posts = append(posts, ForumPost{
-			Title:           translatedTitle,
+			ID:              results[i].Id,
+			Title:           results[i].Title,
 			DiscussionUrl:   commentsUrl,
-			TargetUrl:       res.TargetUrl,
-			TargetUrlDomain: extractDomainFromUrl(res.TargetUrl),
-			CommentCount:    res.CommentCount,
-			Score:           res.Score,
-			TimePosted:      time.Unix(res.TimePosted, 0),
+			TargetUrl:       results[i].TargetUrl,
+			TargetUrlDomain: extractDomainFromUrl(results[i].TargetUrl),
+			CommentCount:    results[i].CommentCount,
+			Score:           results[i].Score,
+			TimePosted:      time.Unix(results[i].TimePosted, 0),
 		})
 	}
 
@@ -1,9 +1,9 @@
 package feed
 
 import (
+	"log/slog"
 	"net/http"
 	"strings"
-	"time"
 )
 
 type lobstersPostResponseJson struct {
@@ -34,7 +34,11 @@ func getLobstersPostsFromFeed(feedUrl string) (ForumPosts, error) {
 	posts := make(ForumPosts, 0, len(feed))
 
 	for i := range feed {
-		createdAt, _ := time.Parse(time.RFC3339, feed[i].CreatedAt)
+		createdAt, err := ParseTime(feed[i].CreatedAt, nil)
+
+		if err != nil {
+			slog.Warn("failed to parse lobsters post time", "value", feed[i].CreatedAt, "error", err)
+		}
 
 		posts = append(posts, ForumPost{
 			Title:           feed[i].Title,
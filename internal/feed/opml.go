@@ -0,0 +1,168 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	Category string        `xml:"category,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    struct {
+		Title       string `xml:"title"`
+		DateCreated string `xml:"dateCreated,omitempty"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []opmlOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+// The category attribute is a comma separated list of slash delimited
+// paths such as "/Tech/Go", only the last segment of the first one is used
+func opmlCategoryAttribute(value string) string {
+	first, _, _ := strings.Cut(value, ",")
+	segments := strings.Split(strings.Trim(strings.TrimSpace(first), "/"), "/")
+
+	return strings.TrimSpace(segments[len(segments)-1])
+}
+
+func collectOPMLOutlines(outlines []opmlOutline, folder string, requests []RSSFeedRequest) []RSSFeedRequest {
+	for i := range outlines {
+		outline := &outlines[i]
+		title := outline.Title
+
+		if title == "" {
+			title = outline.Text
+		}
+
+		if outline.XMLURL == "" {
+			// outlines without a feed are folders
+			requests = collectOPMLOutlines(outline.Outlines, title, requests)
+			continue
+		}
+
+		category := folder
+
+		if outline.Category != "" {
+			category = opmlCategoryAttribute(outline.Category)
+		}
+
+		requests = append(requests, RSSFeedRequest{
+			Url:      outline.XMLURL,
+			Title:    title,
+			Category: category,
+		})
+	}
+
+	return requests
+}
+
+func ParseOPML(contents []byte) ([]RSSFeedRequest, error) {
+	var document opmlDocument
+
+	decoder := xml.NewDecoder(strings.NewReader(string(contents)))
+	decoder.Strict = false
+
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("could not parse OPML: %v", err)
+	}
+
+	requests := collectOPMLOutlines(document.Body.Outlines, "", nil)
+
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("OPML does not contain any feeds")
+	}
+
+	return requests, nil
+}
+
+func FetchOPML(url string) ([]RSSFeedRequest, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := defaultClient.Do(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d for %s", response.StatusCode, url)
+	}
+
+	contents, err := io.ReadAll(response.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseOPML(contents)
+}
+
+// Feeds with a category are placed in a folder with the category's name
+func EncodeOPML(title string, requests []RSSFeedRequest) ([]byte, error) {
+	document := opmlDocument{Version: "2.0"}
+	document.Head.Title = title
+	document.Head.DateCreated = time.Now().Format(time.RFC1123Z)
+
+	folders := make(map[string]int)
+
+	for i := range requests {
+		request := &requests[i]
+		text := request.Title
+
+		if text == "" {
+			text = request.Url
+		}
+
+		outline := opmlOutline{
+			Text:   text,
+			Title:  request.Title,
+			Type:   "rss",
+			XMLURL: request.Url,
+		}
+
+		if request.Category == "" {
+			document.Body.Outlines = append(document.Body.Outlines, outline)
+			continue
+		}
+
+		index, exists := folders[request.Category]
+
+		if !exists {
+			index = len(document.Body.Outlines)
+			folders[request.Category] = index
+			document.Body.Outlines = append(document.Body.Outlines, opmlOutline{Text: request.Category})
+		}
+
+		folder := &document.Body.Outlines[index]
+		folder.Outlines = append(folder.Outlines, outline)
+	}
+
+	encoded, err := xml.MarshalIndent(document, "", "  ")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), encoded...), nil
+}
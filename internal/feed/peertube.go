@@ -0,0 +1,143 @@
+package feed
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Live videos stay published while streaming and wait for the stream before it starts
+const (
+	peertubeStatePublished      = 1
+	peertubeStateWaitingForLive = 4
+)
+
+type peertubeVideosResponseJson struct {
+	Data []struct {
+		Name          string `json:"name"`
+		URL           string `json:"url"`
+		ShortUUID     string `json:"shortUUID"`
+		UUID          string `json:"uuid"`
+		PublishedAt   string `json:"publishedAt"`
+		ThumbnailPath string `json:"thumbnailPath"`
+		PreviewPath   string `json:"previewPath"`
+		Duration      int    `json:"duration"`
+		Views         int    `json:"views"`
+		IsLive        bool   `json:"isLive"`
+		State         struct {
+			ID int `json:"id"`
+		} `json:"state"`
+		Channel struct {
+			DisplayName string `json:"displayName"`
+			URL         string `json:"url"`
+		} `json:"channel"`
+	} `json:"data"`
+}
+
+type peertubeVideoSource struct{}
+
+// Channels are given as host/channel, where the channel can also be in the
+// form of name@host when it's federated from another instance
+func (peertubeVideoSource) ParseChannel(channel string) (string, error) {
+	host, name, found := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(channel, "https://"), "http://"), "/")
+	name = strings.TrimPrefix(strings.Trim(name, "/"), "c/")
+
+	if !found || host == "" || name == "" || strings.Contains(name, "/") {
+		return "", errors.New("expected the channel to be in the form of host/channel")
+	}
+
+	return host + "/" + name, nil
+}
+
+func (peertubeVideoSource) FetchChannelUploads(channels []string, options VideoFetchOptions) (Videos, error) {
+	requests := make([]*http.Request, 0, len(channels))
+
+	for i := range channels {
+		host, name, _ := strings.Cut(channels[i], "/")
+		request, _ := http.NewRequest(
+			"GET",
+			fmt.Sprintf("https://%s/api/v1/video-channels/%s/videos?sort=-publishedAt&count=15", host, url.PathEscape(name)),
+			nil,
+		)
+		requests = append(requests, request)
+	}
+
+	job := newJob(decodeJsonFromRequestTask[peertubeVideosResponseJson](defaultClient), requests).withWorkers(10)
+	responses, errs, err := workerPoolDo(job)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoContent, err)
+	}
+
+	videos := make(Videos, 0, len(channels)*15)
+	var failed int
+
+	for i := range responses {
+		if errs[i] != nil {
+			failed++
+			slog.Error("Failed to fetch peertube channel", "channel", channels[i], "error", errs[i])
+			continue
+		}
+
+		host, _, _ := strings.Cut(channels[i], "/")
+		instance := "https://" + host
+
+		for j := range responses[i].Data {
+			video := &responses[i].Data[j]
+
+			videoUrl := video.URL
+
+			if videoUrl == "" {
+				videoUrl = instance + "/w/" + video.ShortUUID
+			}
+
+			// the preview is the same image as the thumbnail at a higher resolution
+			thumbnailUrl := video.PreviewPath
+
+			if thumbnailUrl == "" {
+				thumbnailUrl = video.ThumbnailPath
+			}
+
+			if thumbnailUrl != "" {
+				thumbnailUrl = instance + thumbnailUrl
+			}
+
+			duration := time.Duration(video.Duration) * time.Second
+			timePosted, err := ParseTime(video.PublishedAt, nil)
+
+			if err != nil {
+				slog.Warn("Failed to parse peertube video time", "value", video.PublishedAt, "error", err)
+			}
+
+			videos = append(videos, Video{
+				ThumbnailUrl: thumbnailUrl,
+				Title:        video.Name,
+				Url:          videoUrl,
+				Author:       video.Channel.DisplayName,
+				AuthorUrl:    video.Channel.URL,
+				TimePosted:   timePosted,
+				Views:        video.Views,
+				Duration:     duration,
+				IsShort:      isShortDuration(duration),
+				IsLive:       video.IsLive && video.State.ID == peertubeStatePublished,
+				IsUpcoming:   video.IsLive && video.State.ID == peertubeStateWaitingForLive,
+			})
+		}
+	}
+
+	if len(videos) == 0 {
+		return nil, ErrNoContent
+	}
+
+	videos.SortByNewest()
+
+	if failed > 0 {
+		return videos, fmt.Errorf("%w: missing videos from %d channels", ErrPartialContent, failed)
+	}
+
+	return videos, nil
+}
@@ -7,6 +7,7 @@ import (
 )
 
 type ForumPost struct {
+	ID              int
 	Title           string
 	DiscussionUrl   string
 	TargetUrl       string
@@ -59,6 +60,13 @@ type Video struct {
 	Author       string
 	AuthorUrl    string
 	TimePosted   time.Time
+	// zero when the source doesn't provide them
+	Views    int
+	Duration time.Duration
+	IsLive   bool
+	// a scheduled premiere or stream that hasn't started yet
+	IsUpcoming bool
+	IsShort    bool
 }
 
 type Videos []Video
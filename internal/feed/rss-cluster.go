@@ -0,0 +1,244 @@
+package feed
+
+import (
+	"hash/fnv"
+	"math"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+type RSSFeedItemSource struct {
+	ChannelName string
+	Link        string
+}
+
+const minHashPermutations = 64
+
+// Coefficients for the permutations used by the MinHash signatures, generated
+// once from a fixed seed so that signatures are comparable between updates
+var minHashCoefficients = func() [minHashPermutations][2]uint64 {
+	var coefficients [minHashPermutations][2]uint64
+	state := uint64(0x9e3779b97f4a7c15)
+
+	next := func() uint64 {
+		// splitmix64
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return z ^ (z >> 31)
+	}
+
+	for i := range coefficients {
+		coefficients[i] = [2]uint64{next() | 1, next()}
+	}
+
+	return coefficients
+}()
+
+// Matched exactly since names such as ref also start legitimate parameters like
+// refid, the utm_ family is the only one that's matched by its prefix
+var trackingQueryParameters = map[string]struct{}{
+	"fbclid":  {},
+	"gclid":   {},
+	"mc_cid":  {},
+	"mc_eid":  {},
+	"ref":     {},
+	"cmpid":   {},
+	"smid":    {},
+	"partner": {},
+}
+
+// Reduces links that point to the same article to the same string by removing
+// the scheme, www prefix, fragment, tracking parameters and trailing slash
+func canonicalLink(link string) string {
+	parsed, err := url.Parse(strings.TrimSpace(link))
+
+	if err != nil || parsed.Host == "" {
+		return strings.ToLower(strings.TrimSpace(link))
+	}
+
+	query := parsed.Query()
+
+	for key := range query {
+		lower := strings.ToLower(key)
+
+		if _, exists := trackingQueryParameters[lower]; exists || strings.HasPrefix(lower, "utm_") {
+			query.Del(key)
+		}
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.")
+	canonical := host + strings.TrimRight(parsed.EscapedPath(), "/")
+
+	if encoded := query.Encode(); encoded != "" {
+		canonical += "?" + encoded
+	}
+
+	return canonical
+}
+
+// Lowercases the title and drops punctuation so that the shingles only
+// depend on the words being used
+func normalizeTitleForShingles(title string) []rune {
+	normalized := make([]rune, 0, len(title))
+	lastWasSpace := true
+
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			normalized = append(normalized, r)
+			lastWasSpace = false
+		} else if !lastWasSpace {
+			normalized = append(normalized, ' ')
+			lastWasSpace = true
+		}
+	}
+
+	return []rune(strings.TrimSpace(string(normalized)))
+}
+
+// Character shingles rather than words so that titles in languages which
+// don't separate words with spaces can be compared as well
+func titleShingles(title string) []uint64 {
+	const size = 4
+
+	runes := normalizeTitleForShingles(title)
+
+	if len(runes) == 0 {
+		return nil
+	}
+
+	if len(runes) < size {
+		return []uint64{hashShingle(runes)}
+	}
+
+	shingles := make([]uint64, 0, len(runes)-size+1)
+
+	for i := 0; i+size <= len(runes); i++ {
+		shingles = append(shingles, hashShingle(runes[i:i+size]))
+	}
+
+	return shingles
+}
+
+func hashShingle(runes []rune) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(string(runes)))
+
+	return hash.Sum64()
+}
+
+type minHashSignature [minHashPermutations]uint64
+
+func newMinHashSignature(shingles []uint64) (minHashSignature, bool) {
+	var signature minHashSignature
+
+	if len(shingles) == 0 {
+		return signature, false
+	}
+
+	for i := range signature {
+		signature[i] = math.MaxUint64
+	}
+
+	for _, shingle := range shingles {
+		for i := range signature {
+			value := minHashCoefficients[i][0]*shingle + minHashCoefficients[i][1]
+
+			if value < signature[i] {
+				signature[i] = value
+			}
+		}
+	}
+
+	return signature, true
+}
+
+// Estimates the Jaccard similarity of the shingle sets the signatures were made from
+func (s *minHashSignature) similarity(other *minHashSignature) float64 {
+	matching := 0
+
+	for i := range s {
+		if s[i] == other[i] {
+			matching++
+		}
+	}
+
+	return float64(matching) / minHashPermutations
+}
+
+type rssItemCluster struct {
+	item      RSSFeedItem
+	signature minHashSignature
+	hasTitle  bool
+}
+
+// Merges items that link to the same article or have similar enough titles,
+// the newest item of each cluster is kept and the channels of the others are
+// listed in AlsoCoveredBy. Expects the items to be sorted by newest
+func (f RSSFeedItems) Cluster(similarityThreshold float64) RSSFeedItems {
+	clusters := make([]*rssItemCluster, 0, len(f))
+	byLink := make(map[string]*rssItemCluster, len(f))
+
+	for i := range f {
+		item := f[i]
+		link := canonicalLink(item.Link)
+		signature, hasTitle := newMinHashSignature(titleShingles(item.Title))
+
+		// items without a link can still be merged by their titles
+		cluster, found := byLink[link]
+		found = found && link != ""
+
+		if !found && hasTitle {
+			for _, candidate := range clusters {
+				if candidate.hasTitle && candidate.signature.similarity(&signature) >= similarityThreshold {
+					cluster = candidate
+					found = true
+					break
+				}
+			}
+		}
+
+		if !found {
+			cluster = &rssItemCluster{
+				item:      item,
+				signature: signature,
+				hasTitle:  hasTitle,
+			}
+
+			clusters = append(clusters, cluster)
+			byLink[link] = cluster
+			continue
+		}
+
+		byLink[link] = cluster
+
+		if cluster.item.ChannelName == item.ChannelName || cluster.coveredBy(item.ChannelName) {
+			continue
+		}
+
+		cluster.item.AlsoCoveredBy = append(cluster.item.AlsoCoveredBy, RSSFeedItemSource{
+			ChannelName: item.ChannelName,
+			Link:        item.Link,
+		})
+	}
+
+	clustered := make(RSSFeedItems, len(clusters))
+
+	for i := range clusters {
+		clustered[i] = clusters[i].item
+	}
+
+	return clustered
+}
+
+func (c *rssItemCluster) coveredBy(channelName string) bool {
+	for i := range c.item.AlsoCoveredBy {
+		if c.item.AlsoCoveredBy[i].ChannelName == channelName {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,200 @@
+package feed
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+var filterAgePattern = regexp.MustCompile(`^(\d+)(m|h|d)$`)
+
+// Rules for which items of a feed to keep. Include and exclude are keywords, or
+// regular expressions when wrapped in slashes, matched case insensitively against
+// the title and description, all other rules are compared case insensitively as a whole
+type RSSFilter struct {
+	Include           []string `yaml:"include"`
+	Exclude           []string `yaml:"exclude"`
+	Categories        []string `yaml:"categories"`
+	ExcludeCategories []string `yaml:"exclude-categories"`
+	Authors           []string `yaml:"authors"`
+	MinAge            string   `yaml:"min-age"`
+	MaxAge            string   `yaml:"max-age"`
+	include           []*regexp.Regexp
+	exclude           []*regexp.Regexp
+	minAge            time.Duration
+	maxAge            time.Duration
+}
+
+func compileFilterPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		expression := regexp.QuoteMeta(pattern)
+
+		// keywords such as C++ would otherwise be invalid expressions
+		if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			expression = pattern[1 : len(pattern)-1]
+		}
+
+		regex, err := regexp.Compile("(?i)" + expression)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %v", pattern, err)
+		}
+
+		compiled = append(compiled, regex)
+	}
+
+	return compiled, nil
+}
+
+func parseFilterAge(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	matches := filterAgePattern.FindStringSubmatch(value)
+
+	if matches == nil {
+		return 0, fmt.Errorf("invalid age %s, must be a number followed by m, h or d", value)
+	}
+
+	amount, _ := strconv.Atoi(matches[1])
+
+	switch matches[2] {
+	case "m":
+		return time.Duration(amount) * time.Minute, nil
+	case "h":
+		return time.Duration(amount) * time.Hour, nil
+	}
+
+	return time.Duration(amount) * 24 * time.Hour, nil
+}
+
+func (f *RSSFilter) Initialize() error {
+	var err error
+
+	if f.include, err = compileFilterPatterns(f.Include); err != nil {
+		return fmt.Errorf("include: %v", err)
+	}
+
+	if f.exclude, err = compileFilterPatterns(f.Exclude); err != nil {
+		return fmt.Errorf("exclude: %v", err)
+	}
+
+	if f.minAge, err = parseFilterAge(f.MinAge); err != nil {
+		return fmt.Errorf("min-age: %v", err)
+	}
+
+	if f.maxAge, err = parseFilterAge(f.MaxAge); err != nil {
+		return fmt.Errorf("max-age: %v", err)
+	}
+
+	if f.maxAge > 0 && f.minAge >= f.maxAge {
+		return fmt.Errorf("min-age must be less than max-age")
+	}
+
+	return nil
+}
+
+func containsFold(values []string, value string) bool {
+	for i := range values {
+		if strings.EqualFold(strings.TrimSpace(values[i]), strings.TrimSpace(value)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func itemAuthors(item *gofeed.Item) []string {
+	authors := make([]string, 0, len(item.Authors))
+
+	for _, author := range item.Authors {
+		if author != nil && author.Name != "" {
+			authors = append(authors, author.Name)
+		}
+	}
+
+	if item.DublinCoreExt != nil {
+		authors = append(authors, item.DublinCoreExt.Creator...)
+	}
+
+	return authors
+}
+
+func (f *RSSFilter) matches(item *gofeed.Item, publishedAt time.Time) bool {
+	age := time.Since(publishedAt)
+
+	if f.minAge > 0 && age < f.minAge {
+		return false
+	}
+
+	if f.maxAge > 0 && age > f.maxAge {
+		return false
+	}
+
+	if len(f.include) > 0 || len(f.exclude) > 0 {
+		text := item.Title + "\n" + HTMLToText(item.Description)
+
+		for _, regex := range f.exclude {
+			if regex.MatchString(text) {
+				return false
+			}
+		}
+
+		if len(f.include) > 0 {
+			included := false
+
+			for _, regex := range f.include {
+				if regex.MatchString(text) {
+					included = true
+					break
+				}
+			}
+
+			if !included {
+				return false
+			}
+		}
+	}
+
+	if len(f.ExcludeCategories) > 0 || len(f.Categories) > 0 {
+		hasCategory := false
+
+		for _, category := range item.Categories {
+			if containsFold(f.ExcludeCategories, category) {
+				return false
+			}
+
+			if containsFold(f.Categories, category) {
+				hasCategory = true
+			}
+		}
+
+		if len(f.Categories) > 0 && !hasCategory {
+			return false
+		}
+	}
+
+	if len(f.Authors) > 0 {
+		hasAuthor := false
+
+		for _, author := range itemAuthors(item) {
+			if containsFold(f.Authors, author) {
+				hasAuthor = true
+				break
+			}
+		}
+
+		if !hasAuthor {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,162 @@
+package feed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// Items without a date are given the time they were first seen rather than the
+// current time, otherwise they would jump to the top on every update
+type rssFirstSeenStore struct {
+	path  string
+	dirty bool
+	// item keys mapped to the unix time they were first seen at
+	items map[string]int64
+	mu    sync.Mutex
+}
+
+// Requests for the same feed persist to the same file, so they share a single
+// store in memory rather than overwriting each other's times when saving them
+var rssFirstSeenStores = struct {
+	stores map[string]*rssFirstSeenStore
+	mu     sync.Mutex
+}{stores: make(map[string]*rssFirstSeenStore)}
+
+func rssItemKey(item *gofeed.Item) string {
+	if item.GUID != "" {
+		return item.GUID
+	}
+
+	return item.Link
+}
+
+// The times are persisted to the data path when one is set so that they survive restarts
+func (r *RSSFeedRequest) firstSeenPath() string {
+	if r.DataPath == "" {
+		return ""
+	}
+
+	hash := sha256.Sum256([]byte(r.Url))
+
+	return filepath.Join(r.DataPath, "rss", "first-seen", hex.EncodeToString(hash[:8])+".json")
+}
+
+func (r *RSSFeedRequest) firstSeenStore() *rssFirstSeenStore {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+
+	if r.state.firstSeen != nil {
+		return r.state.firstSeen
+	}
+
+	path := r.firstSeenPath()
+
+	// without a data path nothing gets persisted, so there's nothing to share
+	if path == "" {
+		r.state.firstSeen = &rssFirstSeenStore{items: make(map[string]int64)}
+		return r.state.firstSeen
+	}
+
+	rssFirstSeenStores.mu.Lock()
+	defer rssFirstSeenStores.mu.Unlock()
+
+	store, exists := rssFirstSeenStores.stores[path]
+
+	if !exists {
+		store = &rssFirstSeenStore{path: path, items: make(map[string]int64)}
+
+		if err := ReadJSONFile(path, &store.items); err != nil {
+			slog.Error("failed to load first seen times of rss items", "path", path, "error", err)
+		}
+
+		if store.items == nil {
+			store.items = make(map[string]int64)
+		}
+
+		rssFirstSeenStores.stores[path] = store
+	}
+
+	r.state.firstSeen = store
+
+	return store
+}
+
+func (r *RSSFeedRequest) firstSeenAt(key string) time.Time {
+	if r.state == nil {
+		return time.Now()
+	}
+
+	store := r.firstSeenStore()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	seenAt, exists := store.items[key]
+
+	if !exists {
+		seenAt = time.Now().Unix()
+		store.items[key] = seenAt
+		store.dirty = true
+	}
+
+	return time.Unix(seenAt, 0)
+}
+
+// Forgets the items that are no longer in the feed and persists any changes
+func (r *RSSFeedRequest) saveFirstSeen(current map[string]struct{}) {
+	if r.state == nil {
+		return
+	}
+
+	store := r.firstSeenStore()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for key := range store.items {
+		if _, exists := current[key]; !exists {
+			delete(store.items, key)
+			store.dirty = true
+		}
+	}
+
+	if !store.dirty {
+		return
+	}
+
+	store.dirty = false
+
+	if store.path != "" {
+		if err := WriteJSONFile(store.path, store.items); err != nil {
+			slog.Error("failed to save first seen times of rss items", "path", store.path, "error", err)
+		}
+	}
+}
+
+// Prefers parsing the dates itself so that the timezone of the feed is used
+// for dates without one, falls back to the dates the feed parser recognized
+func (r *RSSFeedRequest) itemPublishedAt(item *gofeed.Item) (time.Time, bool) {
+	for _, value := range []string{item.Published, item.Updated} {
+		if value == "" {
+			continue
+		}
+
+		if parsed, err := ParseTime(value, r.location); err == nil {
+			return parsed, true
+		}
+	}
+
+	if item.PublishedParsed != nil {
+		return *item.PublishedParsed, true
+	}
+
+	if item.UpdatedParsed != nil {
+		return *item.UpdatedParsed, true
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,104 @@
+package feed
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+type RSSFeedItemEnclosure struct {
+	URL    string
+	Type   string
+	Length int64
+}
+
+var audioFileExtensions = []string{".mp3", ".m4a", ".m4b", ".aac", ".ogg", ".oga", ".opus", ".wav", ".flac"}
+
+func isAudioEnclosure(enclosure *gofeed.Enclosure) bool {
+	if strings.HasPrefix(enclosure.Type, "audio/") {
+		return true
+	}
+
+	if enclosure.Type != "" {
+		return false
+	}
+
+	// some feeds leave out the type, in which case the file extension has to do
+	extension := strings.ToLower(path.Ext(strings.SplitN(enclosure.URL, "?", 2)[0]))
+
+	for _, audioExtension := range audioFileExtensions {
+		if extension == audioExtension {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Prefers audio since podcast feeds sometimes also include a cover image as an enclosure
+func findItemEnclosure(item *gofeed.Item) RSSFeedItemEnclosure {
+	var found *gofeed.Enclosure
+
+	for _, enclosure := range item.Enclosures {
+		if enclosure == nil || enclosure.URL == "" {
+			continue
+		}
+
+		if isAudioEnclosure(enclosure) {
+			found = enclosure
+			break
+		}
+
+		if found == nil {
+			found = enclosure
+		}
+	}
+
+	if found == nil {
+		return RSSFeedItemEnclosure{}
+	}
+
+	length, _ := strconv.ParseInt(strings.TrimSpace(found.Length), 10, 64)
+
+	return RSSFeedItemEnclosure{
+		URL:    found.URL,
+		Type:   found.Type,
+		Length: length,
+	}
+}
+
+// <itunes:duration> is either a number of seconds or in the HH:MM:SS or MM:SS format
+func parseITunesDuration(value string) time.Duration {
+	value = strings.TrimSpace(value)
+
+	if value == "" {
+		return 0
+	}
+
+	parts := strings.Split(value, ":")
+
+	if len(parts) > 3 {
+		return 0
+	}
+
+	var seconds float64
+
+	for _, part := range parts {
+		number, err := strconv.ParseFloat(part, 64)
+
+		if err != nil || number < 0 {
+			return 0
+		}
+
+		seconds = seconds*60 + number
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func (i RSSFeedItem) HasAudio() bool {
+	return i.Enclosure.URL != "" && (strings.HasPrefix(i.Enclosure.Type, "audio/") || i.Enclosure.Type == "")
+}
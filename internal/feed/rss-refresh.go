@@ -0,0 +1,273 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/rss"
+)
+
+// Feeds that ask to be fetched less often than this are still fetched daily
+const rssMaxRefreshHint = 24 * time.Hour
+
+// Feeds are considered due slightly before their refresh time so that they
+// don't miss an update of the widget because it ran a few seconds early
+const rssRefreshLeeway = 30 * time.Second
+
+// What is remembered about a feed between updates so that it doesn't need
+// to be fetched again until it's due and can be fetched conditionally
+type rssFeedState struct {
+	mu           sync.Mutex
+	feed         *gofeed.Feed
+	etag         string
+	lastModified string
+	nextFetch    time.Time
+	firstSeen    *rssFirstSeenStore
+}
+
+// The default translator drops the ttl of RSS feeds, keep it around in Custom
+type rssTranslator struct {
+	gofeed.DefaultRSSTranslator
+}
+
+func (t *rssTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	translated, err := t.DefaultRSSTranslator.Translate(feed)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if rssFeed, ok := feed.(*rss.Feed); ok && rssFeed.TTL != "" {
+		if translated.Custom == nil {
+			translated.Custom = make(map[string]string)
+		}
+
+		translated.Custom["ttl"] = rssFeed.TTL
+	}
+
+	return translated, nil
+}
+
+func (r *RSSFeedRequest) Initialize() error {
+	if r.RefreshInterval != "" {
+		interval, err := parseFilterAge(r.RefreshInterval)
+
+		if err != nil {
+			return fmt.Errorf("refresh-interval: %v", err)
+		}
+
+		r.refreshInterval = interval
+	}
+
+	if r.Timezone != "" {
+		location, err := time.LoadLocation(r.Timezone)
+
+		if err != nil {
+			return fmt.Errorf("timezone: %v", err)
+		}
+
+		r.location = location
+	}
+
+	if r.Filter != nil {
+		if err := r.Filter.Initialize(); err != nil {
+			return fmt.Errorf("filter: %v", err)
+		}
+	}
+
+	if r.state == nil {
+		r.state = &rssFeedState{}
+	}
+
+	return nil
+}
+
+func (r *RSSFeedRequest) GetRefreshInterval() time.Duration {
+	return r.refreshInterval
+}
+
+// <sy:updatePeriod> and <sy:updateFrequency> from the syndication module
+func syndicationRefreshHint(feed *gofeed.Feed) time.Duration {
+	sy, ok := feed.Extensions["sy"]
+
+	if !ok {
+		return 0
+	}
+
+	var period time.Duration
+
+	if values := sy["updatePeriod"]; len(values) > 0 {
+		switch strings.ToLower(strings.TrimSpace(values[0].Value)) {
+		case "hourly":
+			period = time.Hour
+		case "daily":
+			period = 24 * time.Hour
+		case "weekly":
+			period = 7 * 24 * time.Hour
+		case "monthly":
+			period = 30 * 24 * time.Hour
+		case "yearly":
+			period = 365 * 24 * time.Hour
+		}
+	}
+
+	if period == 0 {
+		return 0
+	}
+
+	frequency := 1
+
+	if values := sy["updateFrequency"]; len(values) > 0 {
+		if value, err := strconv.Atoi(strings.TrimSpace(values[0].Value)); err == nil && value > 0 {
+			frequency = value
+		}
+	}
+
+	return period / time.Duration(frequency)
+}
+
+// max-age from Cache-Control, falling back to Expires
+func httpCacheRefreshHint(header http.Header) time.Duration {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.TrimSpace(strings.ToLower(directive))
+
+		if directive == "no-cache" || directive == "no-store" {
+			return 0
+		}
+
+		if value, found := strings.CutPrefix(directive, "max-age="); found {
+			if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+
+	if expires, err := http.ParseTime(header.Get("Expires")); err == nil {
+		if until := time.Until(expires); until > 0 {
+			return until
+		}
+	}
+
+	return 0
+}
+
+// The longest of the intervals the feed and the server it's hosted on ask for
+func rssRefreshHint(feed *gofeed.Feed, header http.Header) time.Duration {
+	hint := httpCacheRefreshHint(header)
+
+	if minutes, err := strconv.Atoi(strings.TrimSpace(feed.Custom["ttl"])); err == nil && minutes > 0 {
+		hint = max(hint, time.Duration(minutes)*time.Minute)
+	}
+
+	hint = max(hint, syndicationRefreshHint(feed))
+
+	return min(hint, rssMaxRefreshHint)
+}
+
+func fetchRSSFeed(ctx context.Context, url string, state *rssFeedState) (*gofeed.Feed, http.Header, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	request.Header.Set("User-Agent", feedParser.UserAgent)
+
+	if state.feed != nil {
+		if state.etag != "" {
+			request.Header.Set("If-None-Match", state.etag)
+		}
+
+		if state.lastModified != "" {
+			request.Header.Set("If-Modified-Since", state.lastModified)
+		}
+	}
+
+	response, err := defaultClient.Do(request)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotModified && state.feed != nil {
+		return state.feed, response.Header, nil
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, nil, fmt.Errorf("unexpected status code %d for %s", response.StatusCode, url)
+	}
+
+	body, err := io.ReadAll(response.Body)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	feed, err := feedParser.Parse(bytes.NewReader(body))
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	state.etag = response.Header.Get("ETag")
+	state.lastModified = response.Header.Get("Last-Modified")
+
+	return feed, response.Header, nil
+}
+
+// Returned along with the last fetched version of a feed that failed to refresh
+var errStaleRSSFeed = errors.New("using the last fetched version")
+
+// Returns the last fetched version of the feed if it isn't due for a refresh yet,
+// otherwise fetches it conditionally. When fetching fails the last version is
+// returned along with an error wrapping errStaleRSSFeed
+func (r *RSSFeedRequest) getFeed() (*gofeed.Feed, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	state := r.state
+
+	if state == nil {
+		return feedParser.ParseURLWithContext(r.Url, ctx)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	now := time.Now()
+
+	if state.feed != nil && now.Add(rssRefreshLeeway).Before(state.nextFetch) {
+		return state.feed, nil
+	}
+
+	feed, header, err := fetchRSSFeed(ctx, r.Url, state)
+
+	if err != nil {
+		if state.feed != nil {
+			return state.feed, fmt.Errorf("%w: %v", errStaleRSSFeed, err)
+		}
+
+		return nil, err
+	}
+
+	state.feed = feed
+
+	if r.refreshInterval > 0 {
+		state.nextFetch = now.Add(r.refreshInterval)
+	} else {
+		state.nextFetch = now.Add(max(r.DefaultRefreshInterval, rssRefreshHint(feed, header)))
+	}
+
+	return feed, nil
+}
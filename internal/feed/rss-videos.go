@@ -0,0 +1,73 @@
+package feed
+
+import (
+	"errors"
+	"net/url"
+	"sync"
+)
+
+// Requests are kept between updates so that their feeds can be fetched conditionally
+var rssVideoFeedRequests = struct {
+	requests map[string]*RSSFeedRequest
+	mu       sync.Mutex
+}{requests: make(map[string]*RSSFeedRequest)}
+
+type rssVideoSource struct{}
+
+func (rssVideoSource) ParseChannel(channel string) (string, error) {
+	parsed, err := url.Parse(channel)
+
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", errors.New("expected the URL of a feed")
+	}
+
+	return channel, nil
+}
+
+func (rssVideoSource) FetchChannelUploads(channels []string, options VideoFetchOptions) (Videos, error) {
+	requests := make([]RSSFeedRequest, 0, len(channels))
+
+	rssVideoFeedRequests.mu.Lock()
+
+	for _, channel := range channels {
+		request, exists := rssVideoFeedRequests.requests[channel]
+
+		if !exists {
+			request = &RSSFeedRequest{Url: channel}
+
+			if err := request.Initialize(); err != nil {
+				rssVideoFeedRequests.mu.Unlock()
+				return nil, err
+			}
+
+			rssVideoFeedRequests.requests[channel] = request
+		}
+
+		requests = append(requests, *request)
+	}
+
+	rssVideoFeedRequests.mu.Unlock()
+
+	items, err := GetItemsFromRSSFeeds(requests)
+
+	if items == nil {
+		return nil, err
+	}
+
+	videos := make(Videos, 0, len(items))
+
+	for i := range items {
+		videos = append(videos, Video{
+			ThumbnailUrl: items[i].ImageURL,
+			Title:        items[i].Title,
+			Url:          items[i].Link,
+			Author:       items[i].ChannelName,
+			AuthorUrl:    items[i].ChannelURL,
+			TimePosted:   items[i].PublishedAt,
+			Duration:     items[i].Duration,
+			IsShort:      isYoutubeShortLink(items[i].Link) || isShortDuration(items[i].Duration),
+		})
+	}
+
+	return videos, err
+}
@@ -1,12 +1,10 @@
 package feed
 
 import (
-	"context"
+	"errors"
 	"fmt"
-	"html"
 	"log/slog"
 	"net/url"
-	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -16,37 +14,36 @@ import (
 )
 
 type RSSFeedItem struct {
-	ChannelName string
-	ChannelURL  string
-	Title       string
-	Link        string
-	ImageURL    string
-	Categories  []string
-	Description string
-	PublishedAt time.Time
+	ChannelName   string
+	ChannelURL    string
+	GUID          string
+	Title         string
+	Link          string
+	ImageURL      string
+	Categories    []string
+	Description   string
+	PublishedAt   time.Time
+	Enclosure     RSSFeedItemEnclosure
+	Duration      time.Duration
+	Unread        bool                `json:"-"`
+	AlsoCoveredBy []RSSFeedItemSource `json:"-"`
+	// in seconds, only set for the podcast style
+	PlaybackPosition int `json:"-"`
 }
 
-// doesn't cover all cases but works the vast majority of the time
-var htmlTagsWithAttributesPattern = regexp.MustCompile(`<\/?[a-zA-Z0-9-]+ *(?:[a-zA-Z-]+=(?:"|').*?(?:"|') ?)* *\/?>`)
-var sequentialWhitespacePattern = regexp.MustCompile(`\s+`)
-
-func sanitizeFeedDescription(description string) string {
-	if description == "" {
-		return ""
+// Identifies the item across updates, falls back to the link since not
+// every feed provides GUIDs
+func (i RSSFeedItem) Key() string {
+	if i.GUID != "" {
+		return i.GUID
 	}
 
-	description = strings.ReplaceAll(description, "\n", " ")
-	description = htmlTagsWithAttributesPattern.ReplaceAllString(description, "")
-	description = sequentialWhitespacePattern.ReplaceAllString(description, " ")
-	description = strings.TrimSpace(description)
-	description = html.UnescapeString(description)
-
-	return description
+	return i.Link
 }
 
 func shortenFeedDescriptionLen(description string, maxLen int) string {
 	description, _ = limitStringLength(description, 1000)
-	description = sanitizeFeedDescription(description)
+	description = HTMLToText(description)
 	description, limited := limitStringLength(description, maxLen)
 
 	if limited {
@@ -57,12 +54,36 @@ func shortenFeedDescriptionLen(description string, maxLen int) string {
 }
 
 type RSSFeedRequest struct {
-	Url             string `yaml:"url"`
-	Title           string `yaml:"title"`
-	HideCategories  bool   `yaml:"hide-categories"`
-	HideDescription bool   `yaml:"hide-description"`
-	ItemLinkPrefix  string `yaml:"item-link-prefix"`
-	IsDetailed      bool   `yaml:"-"`
+	Url             string     `yaml:"url"`
+	Title           string     `yaml:"title"`
+	HideCategories  bool       `yaml:"hide-categories"`
+	HideDescription bool       `yaml:"hide-description"`
+	ItemLinkPrefix  string     `yaml:"item-link-prefix"`
+	Category        string     `yaml:"category"`
+	RefreshInterval string     `yaml:"refresh-interval"`
+	Timezone        string     `yaml:"timezone"`
+	Filter          *RSSFilter `yaml:"filter"`
+	WidgetFilter    *RSSFilter `yaml:"-"`
+	IsDetailed      bool       `yaml:"-"`
+	// how often the feed is fetched when no refresh interval is set and
+	// neither the feed nor the server hosting it ask for a longer one
+	DefaultRefreshInterval time.Duration `yaml:"-"`
+	DataPath               string        `yaml:"-"`
+	refreshInterval        time.Duration
+	location               *time.Location
+	state                  *rssFeedState
+}
+
+func (r *RSSFeedRequest) keepsItem(item *gofeed.Item, publishedAt time.Time) bool {
+	if r.Filter != nil && !r.Filter.matches(item, publishedAt) {
+		return false
+	}
+
+	if r.WidgetFilter != nil && !r.WidgetFilter.matches(item, publishedAt) {
+		return false
+	}
+
+	return true
 }
 
 type RSSFeedItems []RSSFeedItem
@@ -75,25 +96,40 @@ func (f RSSFeedItems) SortByNewest() RSSFeedItems {
 	return f
 }
 
-var feedParser = gofeed.NewParser()
+var feedParser = func() *gofeed.Parser {
+	parser := gofeed.NewParser()
+	parser.RSSTranslator = &rssTranslator{}
 
-func getItemsFromRSSFeedTask(request RSSFeedRequest) ([]RSSFeedItem, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-	defer cancel()
+	return parser
+}()
 
-	feed, err := feedParser.ParseURLWithContext(request.Url, ctx)
+func getItemsFromRSSFeedTask(request RSSFeedRequest) ([]RSSFeedItem, error) {
+	feed, err := request.getFeed()
 
-	if err != nil {
+	if feed == nil {
 		return nil, err
 	}
 
 	items := make(RSSFeedItems, 0, len(feed.Items))
+	undated := make(map[string]struct{})
 
 	for i := range feed.Items {
 		item := feed.Items[i]
+		publishedAt, dated := request.itemPublishedAt(item)
+
+		if !dated {
+			key := rssItemKey(item)
+			undated[key] = struct{}{}
+			publishedAt = request.firstSeenAt(key)
+		}
+
+		if !request.keepsItem(item, publishedAt) {
+			continue
+		}
 
 		rssItem := RSSFeedItem{
 			ChannelURL: feed.Link,
+			GUID:       item.GUID,
 		}
 
 		if request.ItemLinkPrefix != "" {
@@ -156,6 +192,12 @@ func getItemsFromRSSFeedTask(request RSSFeedRequest) ([]RSSFeedItem, error) {
 			rssItem.ChannelName = feed.Title
 		}
 
+		rssItem.Enclosure = findItemEnclosure(item)
+
+		if item.ITunesExt != nil {
+			rssItem.Duration = parseITunesDuration(item.ITunesExt.Duration)
+		}
+
 		if item.Image != nil {
 			rssItem.ImageURL = item.Image.URL
 		} else if url := findThumbnailInItemExtensions(item); url != "" {
@@ -168,16 +210,15 @@ func getItemsFromRSSFeedTask(request RSSFeedRequest) ([]RSSFeedItem, error) {
 			}
 		}
 
-		if item.PublishedParsed != nil {
-			rssItem.PublishedAt = *item.PublishedParsed
-		} else {
-			rssItem.PublishedAt = time.Now()
-		}
+		rssItem.PublishedAt = publishedAt
 
 		items = append(items, rssItem)
 	}
 
-	return items, nil
+	request.saveFirstSeen(undated)
+
+	// a stale feed's items are still displayed but reported as failed
+	return items, err
 }
 
 func recursiveFindThumbnailInExtensions(extensions map[string][]gofeedext.Extension) string {
@@ -219,14 +260,20 @@ func GetItemsFromRSSFeeds(requests []RSSFeedRequest) (RSSFeedItems, error) {
 	}
 
 	failed := 0
+	stale := 0
 
 	entries := make(RSSFeedItems, 0, len(feeds)*10)
 
 	for i := range feeds {
 		if errs[i] != nil {
-			failed++
 			slog.Error("failed to get rss feed", "error", errs[i], "url", requests[i].Url)
-			continue
+
+			if !errors.Is(errs[i], errStaleRSSFeed) {
+				failed++
+				continue
+			}
+
+			stale++
 		}
 
 		entries = append(entries, feeds[i]...)
@@ -238,9 +285,17 @@ func GetItemsFromRSSFeeds(requests []RSSFeedRequest) (RSSFeedItems, error) {
 
 	entries.SortByNewest()
 
+	if failed > 0 && stale > 0 {
+		return entries, fmt.Errorf("%w: missing %d RSS feeds, %d others failed to refresh", ErrPartialContent, failed, stale)
+	}
+
 	if failed > 0 {
 		return entries, fmt.Errorf("%w: missing %d RSS feeds", ErrPartialContent, failed)
 	}
 
+	if stale > 0 {
+		return entries, fmt.Errorf("%w: %d RSS feeds failed to refresh", ErrPartialContent, stale)
+	}
+
 	return entries, nil
 }
@@ -0,0 +1,246 @@
+package feed
+
+import (
+	"html/template"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Elements that are dropped along with everything inside of them
+var sanitizerRemovedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+	atom.Frame: true, atom.Frameset: true, atom.Object: true, atom.Embed: true,
+	atom.Applet: true, atom.Form: true, atom.Input: true, atom.Button: true,
+	atom.Select: true, atom.Textarea: true, atom.Svg: true, atom.Math: true,
+	atom.Link: true, atom.Meta: true, atom.Base: true, atom.Head: true,
+	atom.Title: true, atom.Canvas: true, atom.Dialog: true, atom.Template: true,
+}
+
+// Elements that are kept along with the attributes allowed on them,
+// any other element is replaced by its contents
+var sanitizerAllowedElements = map[atom.Atom][]string{
+	atom.P: nil, atom.Br: nil, atom.Hr: nil, atom.Div: nil, atom.Span: nil,
+	atom.H2: nil, atom.H3: nil, atom.H4: nil, atom.H5: nil, atom.H6: nil,
+	atom.A: {"href", "title"}, atom.Img: {"alt", "title"},
+	atom.Figure: nil, atom.Figcaption: nil, atom.Blockquote: nil,
+	atom.Pre: nil, atom.Code: nil, atom.Kbd: nil, atom.Samp: nil,
+	atom.Ul: nil, atom.Ol: nil, atom.Li: nil, atom.Dl: nil, atom.Dt: nil, atom.Dd: nil,
+	atom.Em: nil, atom.Strong: nil, atom.B: nil, atom.I: nil, atom.U: nil,
+	atom.S: nil, atom.Sub: nil, atom.Sup: nil, atom.Mark: nil, atom.Small: nil,
+	atom.Del: nil, atom.Ins: nil, atom.Q: nil, atom.Cite: nil,
+	atom.Abbr: {"title"}, atom.Time: {"datetime"},
+	atom.Details: nil, atom.Summary: nil,
+	atom.Table: nil, atom.Caption: nil, atom.Thead: nil, atom.Tbody: nil, atom.Tfoot: nil,
+	atom.Tr: nil, atom.Th: {"colspan", "rowspan"}, atom.Td: {"colspan", "rowspan"},
+}
+
+var sanitizerVoidElements = map[atom.Atom]bool{atom.Br: true, atom.Hr: true, atom.Img: true}
+
+// Elements whose contents shouldn't run into the surrounding text when converted to text
+var textBreakElements = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true, atom.Hr: true, atom.Div: true, atom.Li: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Blockquote: true, atom.Pre: true, atom.Tr: true, atom.Td: true, atom.Th: true,
+	atom.Figure: true, atom.Figcaption: true, atom.Dt: true, atom.Dd: true,
+}
+
+func parseHTMLFragment(content string) []*html.Node {
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "div",
+		DataAtom: atom.Div,
+	})
+
+	if err != nil {
+		return nil
+	}
+
+	return nodes
+}
+
+type htmlSanitizer struct {
+	builder strings.Builder
+	base    *url.URL
+}
+
+// Only keeps absolute http(s) URLs, relative ones are resolved against the base
+func (s *htmlSanitizer) resolveURL(value string) string {
+	value = strings.TrimSpace(value)
+
+	if value == "" {
+		return ""
+	}
+
+	parsed, err := url.Parse(value)
+
+	if err != nil {
+		return ""
+	}
+
+	if s.base != nil {
+		parsed = s.base.ResolveReference(parsed)
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return ""
+	}
+
+	return parsed.String()
+}
+
+func getAttribute(node *html.Node, name string) string {
+	for _, attr := range node.Attr {
+		if attr.Namespace == "" && attr.Key == name {
+			return attr.Val
+		}
+	}
+
+	return ""
+}
+
+// Lazy loaded images usually have their actual source in a data attribute
+func imageSource(node *html.Node) string {
+	for _, name := range []string{"data-src", "data-original", "data-lazy-src", "src"} {
+		value := getAttribute(node, name)
+
+		if value != "" && !strings.HasPrefix(value, "data:") {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func (s *htmlSanitizer) write(node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		s.builder.WriteString(html.EscapeString(node.Data))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	if sanitizerRemovedElements[node.DataAtom] {
+		return
+	}
+
+	tag := node.DataAtom
+
+	// the content is always embedded within something that already has a title
+	if tag == atom.H1 {
+		tag = atom.H2
+	}
+
+	allowedAttributes, allowed := sanitizerAllowedElements[tag]
+
+	if !allowed {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			s.write(child)
+		}
+
+		return
+	}
+
+	var src string
+
+	if tag == atom.Img {
+		if src = s.resolveURL(imageSource(node)); src == "" {
+			return
+		}
+	}
+
+	s.builder.WriteString("<" + tag.String())
+
+	if src != "" {
+		s.builder.WriteString(` src="` + html.EscapeString(src) + `"`)
+	}
+
+	for _, name := range allowedAttributes {
+		value := getAttribute(node, name)
+
+		if value == "" {
+			continue
+		}
+
+		if name == "href" {
+			if value = s.resolveURL(value); value == "" {
+				continue
+			}
+		}
+
+		s.builder.WriteString(" " + name + `="` + html.EscapeString(value) + `"`)
+	}
+
+	if tag == atom.A {
+		s.builder.WriteString(` target="_blank" rel="noreferrer"`)
+	}
+
+	s.builder.WriteString(">")
+
+	if sanitizerVoidElements[tag] {
+		return
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		s.write(child)
+	}
+
+	s.builder.WriteString("</" + tag.String() + ">")
+}
+
+// Parses the content and writes it back out with only the elements and attributes
+// known to be safe, which makes it fine to display HTML from untrusted sources.
+// Links and images can only point to http(s) URLs, relative ones are resolved
+// against the base if one is given and dropped otherwise
+func SanitizeHTML(content string, base *url.URL) template.HTML {
+	sanitizer := &htmlSanitizer{base: base}
+
+	for _, node := range parseHTMLFragment(content) {
+		sanitizer.write(node)
+	}
+
+	return template.HTML(sanitizer.builder.String())
+}
+
+func writeHTMLText(builder *strings.Builder, node *html.Node) {
+	if node.Type == html.TextNode {
+		builder.WriteString(node.Data)
+		return
+	}
+
+	if node.Type != html.ElementNode || sanitizerRemovedElements[node.DataAtom] {
+		return
+	}
+
+	if textBreakElements[node.DataAtom] {
+		builder.WriteByte(' ')
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		writeHTMLText(builder, child)
+	}
+
+	if textBreakElements[node.DataAtom] {
+		builder.WriteByte(' ')
+	}
+}
+
+// Returns the text of the content as it would be read with all of the
+// markup removed, entities decoded and whitespace collapsed
+func HTMLToText(content string) string {
+	if content == "" {
+		return ""
+	}
+
+	var builder strings.Builder
+
+	for _, node := range parseHTMLFragment(content) {
+		writeHTMLText(&builder, node)
+	}
+
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
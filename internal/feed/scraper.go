@@ -0,0 +1,208 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glanceapp/glance/internal/parser"
+	"golang.org/x/net/html"
+)
+
+var scraperLinkSelector, _ = parser.CompileSelector("a[href]")
+
+type ScraperField struct {
+	Selector *parser.Selector
+	// the text of the selected element is used when empty
+	Attribute string
+}
+
+// Describes how to turn a list on a web page into items, the selectors
+// of fields are relative to the element of each item
+type ScraperRequest struct {
+	URL         string
+	Items       *parser.Selector
+	Title       ScraperField
+	Link        ScraperField
+	Image       ScraperField
+	Time        ScraperField
+	Description ScraperField
+	Location    *time.Location
+	Renderer    parser.Renderer
+
+	// items without a time are dated by when they first appeared on the page
+	firstSeen   map[string]time.Time
+	firstSeenMu sync.Mutex
+}
+
+func (f *ScraperField) value(row *html.Node, defaultAttributes ...string) (*html.Node, string) {
+	if f.Selector == nil {
+		return nil, ""
+	}
+
+	node := f.Selector.FindOne(row)
+
+	if node == nil {
+		return nil, ""
+	}
+
+	if f.Attribute != "" {
+		return node, parser.NodeValue(node, f.Attribute)
+	}
+
+	for _, attribute := range defaultAttributes {
+		if value := parser.NodeValue(node, attribute); value != "" {
+			return node, value
+		}
+	}
+
+	return node, parser.NodeValue(node, "")
+}
+
+func resolveScrapedURL(base *url.URL, value string) string {
+	// srcset lists candidates separated by commas, the first one is good enough
+	value, _, _ = strings.Cut(strings.TrimSpace(value), ",")
+	value, _, _ = strings.Cut(strings.TrimSpace(value), " ")
+
+	if value == "" {
+		return ""
+	}
+
+	parsed, err := base.Parse(value)
+
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ""
+	}
+
+	return parsed.String()
+}
+
+func findLinkInRow(row *html.Node, titleNode *html.Node) string {
+	for node := titleNode; node != nil && node != row.Parent; node = node.Parent {
+		if node.Type == html.ElementNode && node.Data == "a" {
+			return parser.NodeValue(node, "href")
+		}
+	}
+
+	if row.Type == html.ElementNode && row.Data == "a" {
+		return parser.NodeValue(row, "href")
+	}
+
+	return parser.NodeValue(scraperLinkSelector.FindOne(row), "href")
+}
+
+func (r *ScraperRequest) firstSeenAt(key string, now time.Time, seen map[string]time.Time) time.Time {
+	r.firstSeenMu.Lock()
+	defer r.firstSeenMu.Unlock()
+
+	firstSeen, exists := r.firstSeen[key]
+
+	if !exists {
+		firstSeen = now
+	}
+
+	seen[key] = firstSeen
+
+	return firstSeen
+}
+
+func FetchScrapedItems(ctx context.Context, request *ScraperRequest) (RSSFeedItems, error) {
+	page, err := request.Renderer.Render(ctx, request.URL)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoContent, err)
+	}
+
+	doc, err := parser.ParseHTMLs(page.HTML)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoContent, err)
+	}
+
+	base, err := url.Parse(page.URL)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoContent, err)
+	}
+
+	rows := request.Items.FindAll(doc)
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: items selector %s did not match anything", ErrNoContent, request.Items)
+	}
+
+	channelName := strings.TrimPrefix(base.Hostname(), "www.")
+	now := time.Now()
+	seen := make(map[string]time.Time, len(rows))
+	items := make(RSSFeedItems, 0, len(rows))
+
+	for _, row := range rows {
+		titleNode, title := request.Title.value(row)
+
+		if title == "" {
+			continue
+		}
+
+		item := RSSFeedItem{
+			ChannelName: channelName,
+			ChannelURL:  request.URL,
+			Title:       shortenFeedDescriptionLen(title, 200),
+		}
+
+		var link string
+
+		if request.Link.Selector != nil {
+			_, link = request.Link.value(row, "href")
+		} else if titleNode != nil {
+			link = findLinkInRow(row, titleNode)
+		}
+
+		item.Link = resolveScrapedURL(base, link)
+
+		if item.Link == "" {
+			item.Link = page.URL
+			// items without their own link are told apart by their title
+			item.GUID = page.URL + "#" + title
+		}
+
+		// lazy loaded images keep a placeholder in src
+		if _, image := request.Image.value(row, "data-src", "data-original", "src", "srcset"); image != "" {
+			item.ImageURL = resolveScrapedURL(base, image)
+		}
+
+		if _, description := request.Description.value(row); description != "" {
+			item.Description = shortenFeedDescriptionLen(description, 200)
+		}
+
+		if _, value := request.Time.value(row, "datetime"); value != "" {
+			publishedAt, err := ParseTime(value, request.Location)
+
+			if err != nil {
+				slog.Warn("Failed to parse scraped time", "url", request.URL, "value", value, "error", err)
+			} else {
+				item.PublishedAt = publishedAt
+			}
+		}
+
+		if item.PublishedAt.IsZero() {
+			item.PublishedAt = request.firstSeenAt(item.Key(), now, seen)
+		}
+
+		items = append(items, item)
+	}
+
+	// only remember items that are still on the page so that the map doesn't grow forever
+	request.firstSeenMu.Lock()
+	request.firstSeen = seen
+	request.firstSeenMu.Unlock()
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: none of the items had a title", ErrNoContent)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,57 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Reads state persisted with WriteJSONFile, a missing file is not treated as
+// an error and leaves the value untouched
+func ReadJSONFile(path string, value any) error {
+	contents, err := os.ReadFile(path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(contents, value)
+}
+
+// Writes to a temporary file first so that a crash mid write doesn't leave
+// behind a truncated file
+func WriteJSONFile(path string, value any) error {
+	contents, err := json.Marshal(value)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := temp.Write(contents); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return err
+	}
+
+	if err := temp.Close(); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+
+	return os.Rename(temp.Name(), path)
+}
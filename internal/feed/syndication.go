@@ -0,0 +1,401 @@
+package feed
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/url"
+	"time"
+)
+
+// A feed made out of the content of a widget so that it can be consumed by other readers
+type SyndicationFeed struct {
+	Title   string
+	HomeURL string
+	FeedURL string
+	Items   []SyndicationItem
+}
+
+type SyndicationItem struct {
+	ID    string
+	Title string
+	URL   string
+	// the page the item is about when it's a discussion of it, such as forum posts
+	ExternalURL string
+	Summary     string
+	ContentHTML string
+	ImageURL    string
+	AuthorName  string
+	AuthorURL   string
+	Published   time.Time
+	Tags        []string
+	Attachment  *SyndicationAttachment
+}
+
+type SyndicationAttachment struct {
+	URL      string
+	MimeType string
+	Length   int64
+	Duration time.Duration
+}
+
+func (f RSSFeedItems) ToSyndicationItems() []SyndicationItem {
+	items := make([]SyndicationItem, 0, len(f))
+
+	for i := range f {
+		item := &f[i]
+		syndicated := SyndicationItem{
+			ID:         item.Key(),
+			Title:      item.Title,
+			URL:        item.Link,
+			Summary:    item.Description,
+			ImageURL:   item.ImageURL,
+			AuthorName: item.ChannelName,
+			AuthorURL:  item.ChannelURL,
+			Published:  item.PublishedAt,
+			Tags:       item.Categories,
+		}
+
+		if item.Enclosure.URL != "" {
+			syndicated.Attachment = &SyndicationAttachment{
+				URL:      item.Enclosure.URL,
+				MimeType: item.Enclosure.Type,
+				Length:   item.Enclosure.Length,
+				Duration: item.Duration,
+			}
+		}
+
+		items = append(items, syndicated)
+	}
+
+	return items
+}
+
+func (p ForumPosts) ToSyndicationItems() []SyndicationItem {
+	items := make([]SyndicationItem, 0, len(p))
+
+	for i := range p {
+		post := &p[i]
+		syndicated := SyndicationItem{
+			ID:        post.DiscussionUrl,
+			Title:     post.Title,
+			URL:       post.DiscussionUrl,
+			ImageURL:  post.ThumbnailUrl,
+			Published: post.TimePosted,
+			Tags:      post.Tags,
+		}
+
+		if post.TargetUrl != "" && post.TargetUrl != post.DiscussionUrl {
+			syndicated.ExternalURL = post.TargetUrl
+		}
+
+		syndicated.ContentHTML = fmt.Sprintf(
+			`<p>%d points · <a href="%s">%d comments</a></p>`,
+			post.Score,
+			html.EscapeString(post.DiscussionUrl),
+			post.CommentCount,
+		)
+
+		items = append(items, syndicated)
+	}
+
+	return items
+}
+
+func (r AppReleases) ToSyndicationItems() []SyndicationItem {
+	items := make([]SyndicationItem, 0, len(r))
+
+	for i := range r {
+		release := &r[i]
+
+		items = append(items, SyndicationItem{
+			ID:        release.NotesUrl,
+			Title:     release.Name + " " + release.Version,
+			URL:       release.NotesUrl,
+			Published: release.TimeReleased,
+			Tags:      []string{string(release.Source)},
+		})
+	}
+
+	return items
+}
+
+func (v Videos) ToSyndicationItems() []SyndicationItem {
+	items := make([]SyndicationItem, 0, len(v))
+
+	for i := range v {
+		video := &v[i]
+
+		items = append(items, SyndicationItem{
+			ID:         video.Url,
+			Title:      video.Title,
+			URL:        video.Url,
+			ImageURL:   video.ThumbnailUrl,
+			AuthorName: video.Author,
+			AuthorURL:  video.AuthorUrl,
+			Published:  video.TimePosted,
+		})
+	}
+
+	return items
+}
+
+// The last time anything in the feed changed as far as can be told
+func (f *SyndicationFeed) updated() time.Time {
+	var updated time.Time
+
+	for i := range f.Items {
+		if f.Items[i].Published.After(updated) {
+			updated = f.Items[i].Published
+		}
+	}
+
+	if updated.IsZero() {
+		return time.Now()
+	}
+
+	return updated
+}
+
+// Atom requires IDs to be IRIs, which GUIDs from RSS feeds often aren't
+func atomID(id string) string {
+	if parsed, err := url.Parse(id); err == nil && parsed.Scheme != "" {
+		return id
+	}
+
+	hash := sha1.Sum([]byte(id))
+
+	return "urn:sha1:" + hex.EncodeToString(hash[:])
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomThumbnail struct {
+	URL string `xml:"url,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+	Thumbnail  *atomThumbnail `xml:"media:thumbnail,omitempty"`
+}
+
+type atomFeed struct {
+	XMLName    xml.Name    `xml:"feed"`
+	Namespace  string      `xml:"xmlns,attr"`
+	MediaSpace string      `xml:"xmlns:media,attr"`
+	ID         string      `xml:"id"`
+	Title      string      `xml:"title"`
+	Updated    string      `xml:"updated"`
+	Generator  string      `xml:"generator"`
+	Links      []atomLink  `xml:"link"`
+	Author     atomPerson  `xml:"author"`
+	Entries    []atomEntry `xml:"entry"`
+}
+
+func (f *SyndicationFeed) EncodeAtom() ([]byte, error) {
+	updated := f.updated()
+
+	document := atomFeed{
+		Namespace:  "http://www.w3.org/2005/Atom",
+		MediaSpace: "http://search.yahoo.com/mrss/",
+		ID:         f.FeedURL,
+		Title:      f.Title,
+		Updated:    updated.Format(time.RFC3339),
+		Generator:  "Glance",
+		Links:      []atomLink{{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"}},
+		// entries without an author inherit this one, one of the two is required
+		Author:  atomPerson{Name: f.Title},
+		Entries: make([]atomEntry, 0, len(f.Items)),
+	}
+
+	if f.HomeURL != "" {
+		document.Links = append(document.Links, atomLink{Href: f.HomeURL, Rel: "alternate"})
+	}
+
+	for i := range f.Items {
+		item := &f.Items[i]
+		published := item.Published
+
+		if published.IsZero() {
+			published = updated
+		}
+
+		entry := atomEntry{
+			ID:        atomID(item.ID),
+			Title:     item.Title,
+			Updated:   published.Format(time.RFC3339),
+			Published: published.Format(time.RFC3339),
+		}
+
+		if item.ExternalURL != "" {
+			entry.Links = append(entry.Links, atomLink{Href: item.ExternalURL, Rel: "alternate"})
+			entry.Links = append(entry.Links, atomLink{Href: item.URL, Rel: "related"})
+		} else if item.URL != "" {
+			entry.Links = append(entry.Links, atomLink{Href: item.URL, Rel: "alternate"})
+		}
+
+		if item.Attachment != nil {
+			entry.Links = append(entry.Links, atomLink{
+				Href:   item.Attachment.URL,
+				Rel:    "enclosure",
+				Type:   item.Attachment.MimeType,
+				Length: item.Attachment.Length,
+			})
+		}
+
+		if item.AuthorName != "" {
+			entry.Author = &atomPerson{Name: item.AuthorName, URI: item.AuthorURL}
+		}
+
+		for _, tag := range item.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+
+		if item.Summary != "" {
+			entry.Summary = &atomText{Type: "text", Value: item.Summary}
+		}
+
+		if item.ContentHTML != "" {
+			entry.Content = &atomText{Type: "html", Value: item.ContentHTML}
+		}
+
+		if item.ImageURL != "" {
+			entry.Thumbnail = &atomThumbnail{URL: item.ImageURL}
+		}
+
+		document.Entries = append(document.Entries, entry)
+	}
+
+	encoded, err := xml.MarshalIndent(document, "", "  ")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), encoded...), nil
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type jsonFeedAttachment struct {
+	URL               string `json:"url"`
+	MimeType          string `json:"mime_type"`
+	SizeInBytes       int64  `json:"size_in_bytes,omitempty"`
+	DurationInSeconds int64  `json:"duration_in_seconds,omitempty"`
+}
+
+type jsonFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url,omitempty"`
+	ExternalURL   string               `json:"external_url,omitempty"`
+	Title         string               `json:"title,omitempty"`
+	ContentHTML   string               `json:"content_html,omitempty"`
+	ContentText   string               `json:"content_text,omitempty"`
+	Summary       string               `json:"summary,omitempty"`
+	Image         string               `json:"image,omitempty"`
+	DatePublished string               `json:"date_published,omitempty"`
+	Authors       []jsonFeedAuthor     `json:"authors,omitempty"`
+	Tags          []string             `json:"tags,omitempty"`
+	Attachments   []jsonFeedAttachment `json:"attachments,omitempty"`
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+func (f *SyndicationFeed) EncodeJSONFeed() ([]byte, error) {
+	document := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.HomeURL,
+		FeedURL:     f.FeedURL,
+		Items:       make([]jsonFeedItem, 0, len(f.Items)),
+	}
+
+	for i := range f.Items {
+		item := &f.Items[i]
+		encoded := jsonFeedItem{
+			ID:          item.ID,
+			URL:         item.URL,
+			ExternalURL: item.ExternalURL,
+			Title:       item.Title,
+			ContentHTML: item.ContentHTML,
+			Summary:     item.Summary,
+			Image:       item.ImageURL,
+			Tags:        item.Tags,
+		}
+
+		// every item needs either content_html or content_text
+		if encoded.ContentHTML == "" {
+			encoded.ContentText = item.Summary
+
+			if encoded.ContentText == "" {
+				encoded.ContentText = item.Title
+			}
+		}
+
+		if !item.Published.IsZero() {
+			encoded.DatePublished = item.Published.Format(time.RFC3339)
+		}
+
+		if item.AuthorName != "" {
+			encoded.Authors = []jsonFeedAuthor{{Name: item.AuthorName, URL: item.AuthorURL}}
+		}
+
+		if item.Attachment != nil {
+			mimeType := item.Attachment.MimeType
+
+			if mimeType == "" {
+				mimeType = "application/octet-stream"
+			}
+
+			encoded.Attachments = []jsonFeedAttachment{{
+				URL:               item.Attachment.URL,
+				MimeType:          mimeType,
+				SizeInBytes:       item.Attachment.Length,
+				DurationInSeconds: int64(item.Attachment.Duration / time.Second),
+			}}
+		}
+
+		document.Items = append(document.Items, encoded)
+	}
+
+	return json.MarshalIndent(document, "", "  ")
+}
@@ -0,0 +1,257 @@
+package feed
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sites in mainland China publish their times in China Standard Time,
+// which has no daylight saving time
+var chinaTimezone = time.FixedZone("CST", 8*60*60)
+
+// Layouts which include the year, tried in order. Layouts without a zone
+// are interpreted in the location given to ParseTime
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04:05 -0700 (MST)",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"Mon, 2 Jan 2006 15:04:05",
+	"Mon, 2 January 2006 15:04:05 -0700",
+	"Mon, 2 January 2006 15:04:05 MST",
+	"Monday, 2 January 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04 MST",
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.UnixDate,
+	time.ANSIC,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05 -0700 MST",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-1-2 15:04:05",
+	"2006-1-2 15:04",
+	"2006-1-2",
+	"2006/1/2 15:04:05",
+	"2006/1/2 15:04",
+	"2006/1/2",
+	"2006.1.2 15:04",
+	"2006.1.2",
+	"2006年1月2日 15:04:05",
+	"2006年1月2日 15:04",
+	"2006年1月2日15:04",
+	"2006年1月2日",
+	"January 2, 2006 15:04",
+	"January 2, 2006",
+	"Jan 2, 2006 15:04",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+}
+
+// Layouts without the year, which is assumed to be the current one
+// unless that would place the time in the future
+var yearlessTimeLayouts = []string{
+	"1-2 15:04",
+	"1-2",
+	"1月2日 15:04",
+	"1月2日15:04",
+	"1月2日",
+}
+
+// Only the zones defined by RFC 822 and a few unambiguous ones, Go gives
+// abbreviations it doesn't know about an offset of zero. CST is left out since
+// it's also China Standard Time, see fixTimezoneAbbreviation
+var timezoneAbbreviationOffsets = map[string]int{
+	"EST": -5, "EDT": -4,
+	"MST": -7, "MDT": -6, "PST": -8, "PDT": -7,
+	"AKST": -9, "AKDT": -8, "HST": -10,
+	"CET": 1, "CEST": 2, "EET": 2, "EEST": 3, "WET": 0, "WEST": 1, "BST": 1,
+	"MSK": 3, "JST": 9, "KST": 9, "HKT": 8, "SGT": 8,
+	"AEST": 10, "AEDT": 11, "ACST": 9, "AWST": 8, "NZST": 12, "NZDT": 13,
+}
+
+var (
+	chineseRelativeTimePattern = regexp.MustCompile(`^(\d+)\s*(秒|秒钟|分|分钟|小时|个小时|天|日|周|星期|个星期|月|个月|年)前$`)
+	englishRelativeTimePattern = regexp.MustCompile(`^(\d+|an?|one)\s+(second|sec|minute|min|hour|hr|day|week|month|year)s?\s+ago$`)
+	relativeDayPattern         = regexp.MustCompile(`^(今天|昨天|前天|today|yesterday)(?:\s*(\d{1,2}):(\d{2}))?$`)
+	unixTimestampPattern       = regexp.MustCompile(`^\d{10}(\d{3})?$`)
+)
+
+// Corrects the offset of times parsed with a zone abbreviation that Go didn't
+// know. Abbreviations used by the location the time was parsed in already have
+// the right offset, such that CST is only taken to be China Standard Time when
+// the location doesn't say otherwise, as in America/Chicago
+func fixTimezoneAbbreviation(parsed time.Time) time.Time {
+	name, offset := parsed.Zone()
+
+	if offset != 0 {
+		return parsed
+	}
+
+	if name == "CST" {
+		year, month, day := parsed.Date()
+		hour, minute, second := parsed.Clock()
+
+		return time.Date(year, month, day, hour, minute, second, parsed.Nanosecond(), chinaTimezone)
+	}
+
+	hours, known := timezoneAbbreviationOffsets[name]
+
+	if !known || hours == 0 {
+		return parsed
+	}
+
+	year, month, day := parsed.Date()
+	hour, minute, second := parsed.Clock()
+
+	return time.Date(year, month, day, hour, minute, second, parsed.Nanosecond(), time.FixedZone(name, hours*60*60))
+}
+
+func relativeTimeUnitToDuration(unit string) (time.Duration, int, int) {
+	switch unit {
+	case "秒", "秒钟", "second", "sec":
+		return time.Second, 0, 0
+	case "分", "分钟", "minute", "min":
+		return time.Minute, 0, 0
+	case "小时", "个小时", "hour", "hr":
+		return time.Hour, 0, 0
+	case "天", "日", "day":
+		return 24 * time.Hour, 0, 0
+	case "周", "星期", "个星期", "week":
+		return 7 * 24 * time.Hour, 0, 0
+	case "月", "个月", "month":
+		return 0, 1, 0
+	}
+
+	return 0, 0, 1
+}
+
+func parseRelativeTime(value string, now time.Time) (time.Time, bool) {
+	switch value {
+	case "刚刚", "刚才", "just now", "now":
+		return now, true
+	}
+
+	var amount int
+	var unit string
+
+	if matches := chineseRelativeTimePattern.FindStringSubmatch(value); matches != nil {
+		amount, _ = strconv.Atoi(matches[1])
+		unit = matches[2]
+	} else if matches := englishRelativeTimePattern.FindStringSubmatch(value); matches != nil {
+		if amount, _ = strconv.Atoi(matches[1]); amount == 0 {
+			amount = 1
+		}
+
+		unit = matches[2]
+	} else if matches := relativeDayPattern.FindStringSubmatch(value); matches != nil {
+		daysAgo := 0
+
+		switch matches[1] {
+		case "昨天", "yesterday":
+			daysAgo = 1
+		case "前天":
+			daysAgo = 2
+		}
+
+		hour, minute := 0, 0
+
+		if matches[2] != "" {
+			hour, _ = strconv.Atoi(matches[2])
+			minute, _ = strconv.Atoi(matches[3])
+		}
+
+		year, month, day := now.AddDate(0, 0, -daysAgo).Date()
+
+		return time.Date(year, month, day, hour, minute, 0, 0, now.Location()), true
+	} else {
+		return time.Time{}, false
+	}
+
+	duration, months, years := relativeTimeUnitToDuration(unit)
+
+	if duration > 0 {
+		return now.Add(-time.Duration(amount) * duration), true
+	}
+
+	return now.AddDate(-amount*years, -amount*months, 0), true
+}
+
+func parseTimeRelativeTo(value string, location *time.Location, now time.Time) (time.Time, error) {
+	value = strings.Join(strings.Fields(value), " ")
+
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty time")
+	}
+
+	if location == nil {
+		location = time.UTC
+	}
+
+	now = now.In(location)
+
+	if unixTimestampPattern.MatchString(value) {
+		timestamp, _ := strconv.ParseInt(value, 10, 64)
+
+		if len(value) == 13 {
+			return time.UnixMilli(timestamp), nil
+		}
+
+		return time.Unix(timestamp, 0), nil
+	}
+
+	if parsed, ok := parseRelativeTime(strings.ToLower(value), now); ok {
+		return parsed, nil
+	}
+
+	for _, layout := range timeLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, location); err == nil {
+			return fixTimezoneAbbreviation(parsed), nil
+		}
+	}
+
+	for _, layout := range yearlessTimeLayouts {
+		parsed, err := time.ParseInLocation(layout, value, location)
+
+		if err != nil {
+			continue
+		}
+
+		parsed = parsed.AddDate(now.Year()-parsed.Year(), 0, 0)
+
+		// a day late is fine since the source may be in a timezone that's ahead
+		if parsed.After(now.Add(24 * time.Hour)) {
+			parsed = parsed.AddDate(-1, 0, 0)
+		}
+
+		return parsed, nil
+	}
+
+	return time.Time{}, fmt.Errorf("unsupported time format %q", value)
+}
+
+// Parses times in the formats commonly found in feeds and on web pages, including
+// RFC 3339 and RFC 1123 variants, unix timestamps, dates without a year and relative
+// times such as "3小时前", "昨天 12:30" or "2 hours ago". Times without a zone
+// are interpreted in the given location, or in UTC if it's nil
+func ParseTime(value string, location *time.Location) (time.Time, error) {
+	return parseTimeRelativeTo(value, location, time.Now())
+}
@@ -0,0 +1,43 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/glanceapp/glance/internal/tool"
+)
+
+// Translates all of the texts in one call so that the translator can batch
+// them, texts that fail to translate are kept as they are and reported
+// through the returned error
+func TranslateTexts(ctx context.Context, translator tool.Translator, texts []string, language string) ([]string, error) {
+	results, err := translator.Translate(ctx, texts, tool.AutoDetectLanguage, language)
+
+	if err != nil {
+		slog.Error("Failed to translate texts", "language", language, "error", err)
+	}
+
+	translated := make([]string, len(texts))
+	failed := 0
+
+	for i := range texts {
+		if i >= len(results) || results[i] == "" {
+			translated[i] = texts[i]
+
+			if texts[i] != "" {
+				failed++
+			}
+
+			continue
+		}
+
+		translated[i] = results[i]
+	}
+
+	if failed > 0 {
+		return translated, fmt.Errorf("%w: could not translate %d of %d texts", ErrPartialContent, failed, len(texts))
+	}
+
+	return translated, nil
+}
@@ -0,0 +1,192 @@
+package feed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/glanceapp/glance/internal/tool"
+)
+
+const translationCacheMaxEntries = 10000
+
+type translationCacheEntry struct {
+	Text string `json:"text"`
+	// unix time of when the entry was last used, the least recently used entries
+	// are dropped first once the cache is full
+	UsedAt int64 `json:"used-at"`
+}
+
+// Wraps a translator so that every text is only ever translated once per
+// backend and language pair, entries are persisted to the data path when one
+// is set so that restarts don't translate everything again
+type CachedTranslator struct {
+	translator tool.Translator
+	backend    string
+	path       string
+	maxEntries int
+	loaded     bool
+	entries    map[string]translationCacheEntry
+	mu         sync.Mutex
+}
+
+// The backend identifies the translator in the cache keys, such that changing
+// the service or the model doesn't return translations made by the previous one
+func NewCachedTranslator(translator tool.Translator, backend string, dataPath string) *CachedTranslator {
+	cache := &CachedTranslator{
+		translator: translator,
+		backend:    backend,
+		maxEntries: translationCacheMaxEntries,
+		entries:    make(map[string]translationCacheEntry),
+	}
+
+	if dataPath != "" {
+		cache.path = filepath.Join(dataPath, "translations.json")
+	}
+
+	return cache
+}
+
+func (c *CachedTranslator) key(text, from, to string) string {
+	hash := sha256.Sum256([]byte(c.backend + "\x00" + from + "\x00" + to + "\x00" + text))
+
+	return hex.EncodeToString(hash[:16])
+}
+
+func (c *CachedTranslator) load() {
+	if c.loaded {
+		return
+	}
+
+	c.loaded = true
+
+	if c.path == "" {
+		return
+	}
+
+	if err := ReadJSONFile(c.path, &c.entries); err != nil {
+		slog.Error("failed to load translation cache", "path", c.path, "error", err)
+	}
+
+	if c.entries == nil {
+		c.entries = make(map[string]translationCacheEntry)
+	}
+}
+
+// Drops the least recently used entries, with some room to spare so
+// that it doesn't have to happen again on every new translation
+func (c *CachedTranslator) evict() {
+	if len(c.entries) <= c.maxEntries {
+		return
+	}
+
+	keys := make([]string, 0, len(c.entries))
+
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].UsedAt < c.entries[keys[j]].UsedAt
+	})
+
+	for _, key := range keys[:len(keys)-c.maxEntries*9/10] {
+		delete(c.entries, key)
+	}
+}
+
+func (c *CachedTranslator) save() {
+	if c.path == "" {
+		return
+	}
+
+	if err := WriteJSONFile(c.path, c.entries); err != nil {
+		slog.Error("failed to save translation cache", "path", c.path, "error", err)
+	}
+}
+
+// Only the texts that aren't cached are sent to the translator, in a single call
+func (c *CachedTranslator) Translate(ctx context.Context, texts []string, from, to string) ([]string, error) {
+	results := make([]string, len(texts))
+	keys := make([]string, len(texts))
+	now := time.Now().Unix()
+
+	var missing []string
+	var missingIndexes []int
+	pending := make(map[string]int)
+
+	c.mu.Lock()
+	c.load()
+
+	for i, text := range texts {
+		if text == "" {
+			continue
+		}
+
+		keys[i] = c.key(text, from, to)
+
+		if entry, exists := c.entries[keys[i]]; exists {
+			entry.UsedAt = now
+			c.entries[keys[i]] = entry
+			results[i] = entry.Text
+			continue
+		}
+
+		// the same text appearing more than once is only translated once
+		if _, exists := pending[keys[i]]; !exists {
+			pending[keys[i]] = len(missing)
+			missing = append(missing, text)
+		}
+
+		missingIndexes = append(missingIndexes, i)
+	}
+
+	c.mu.Unlock()
+
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	translated, err := c.translator.Translate(ctx, missing, from, to)
+
+	if len(translated) != len(missing) {
+		if err == nil {
+			err = errors.New("translator returned the wrong number of translations")
+		}
+
+		return results, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	added := false
+
+	for _, i := range missingIndexes {
+		text := translated[pending[keys[i]]]
+
+		if text == "" {
+			continue
+		}
+
+		results[i] = text
+
+		if _, exists := c.entries[keys[i]]; !exists {
+			c.entries[keys[i]] = translationCacheEntry{Text: text, UsedAt: now}
+			added = true
+		}
+	}
+
+	if added {
+		c.evict()
+		c.save()
+	}
+
+	return results, err
+}
@@ -3,6 +3,7 @@ package feed
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/url"
 	"regexp"
 	"slices"
@@ -96,10 +97,12 @@ func limitStringLength(s string, max int) (string, bool) {
 	return s, false
 }
 
+// Falls back to the current time so that things with a malformed time are still displayed
 func parseRFC3339Time(t string) time.Time {
-	parsed, err := time.Parse(time.RFC3339, t)
+	parsed, err := ParseTime(t, nil)
 
 	if err != nil {
+		slog.Warn("failed to parse time", "value", t, "error", err)
 		return time.Now()
 	}
 
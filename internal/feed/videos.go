@@ -0,0 +1,282 @@
+package feed
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type VideoFetchOptions struct {
+	VideoUrlTemplate string
+	IncludeShorts    bool
+	// videos whose duration isn't known are kept
+	MinDuration  time.Duration
+	MaxDuration  time.Duration
+	HideUpcoming bool
+}
+
+// Sites without a dedicated format for short videos have their shorts
+// detected by their duration alone
+const shortVideoMaxDuration = time.Minute
+
+func isShortDuration(duration time.Duration) bool {
+	return duration > 0 && duration <= shortVideoMaxDuration
+}
+
+func (o *VideoFetchOptions) keepsVideo(video *Video) bool {
+	if video.IsShort && !o.IncludeShorts {
+		return false
+	}
+
+	if video.IsUpcoming && o.HideUpcoming {
+		return false
+	}
+
+	if video.Duration > 0 {
+		if o.MinDuration > 0 && video.Duration < o.MinDuration {
+			return false
+		}
+
+		if o.MaxDuration > 0 && video.Duration > o.MaxDuration {
+			return false
+		}
+	}
+
+	return true
+}
+
+// A site that videos can be fetched from, channels are given without the
+// prefix the source is registered under
+type VideoSource interface {
+	// Validates the channel and returns it in the form expected by FetchChannelUploads
+	ParseChannel(channel string) (string, error)
+	FetchChannelUploads(channels []string, options VideoFetchOptions) (Videos, error)
+}
+
+var videoSources = map[string]VideoSource{
+	"youtube":  youtubeVideoSource{},
+	"bilibili": bilibiliVideoSource{},
+	"peertube": peertubeVideoSource{},
+	"rss":      rssVideoSource{},
+}
+
+type VideoChannel struct {
+	Source string
+	ID     string
+}
+
+func (c VideoChannel) String() string {
+	return c.Source + ":" + c.ID
+}
+
+var bilibiliChannelPattern = regexp.MustCompile(`^\d+$`)
+
+// Parses a channel entry in the form of source:channel. Entries without a
+// prefix are YouTube channels, unless they're entirely made up of digits in
+// which case they're the ID of a Bilibili user
+func ParseVideoChannel(entry string) (VideoChannel, error) {
+	entry = strings.TrimSpace(entry)
+
+	if entry == "" {
+		return VideoChannel{}, errors.New("channel is empty")
+	}
+
+	channel := VideoChannel{Source: "youtube", ID: entry}
+
+	if prefix, id, found := strings.Cut(entry, ":"); found {
+		if _, known := videoSources[prefix]; !known {
+			return VideoChannel{}, fmt.Errorf("unknown video source %q in %q", prefix, entry)
+		}
+
+		channel = VideoChannel{Source: prefix, ID: id}
+	} else if bilibiliChannelPattern.MatchString(entry) {
+		channel.Source = "bilibili"
+	}
+
+	id, err := videoSources[channel.Source].ParseChannel(channel.ID)
+
+	if err != nil {
+		return VideoChannel{}, fmt.Errorf("%s channel %q: %v", channel.Source, channel.ID, err)
+	}
+
+	channel.ID = id
+
+	return channel, nil
+}
+
+type videoSourceBatch struct {
+	source   string
+	channels []string
+}
+
+// Fetches the uploads of channels from any number of sources, each source
+// is fetched from concurrently and the results are merged by newest
+func FetchVideoChannelUploads(channels []VideoChannel, options VideoFetchOptions) (Videos, error) {
+	batches := make([]videoSourceBatch, 0, len(videoSources))
+	batchIndexes := make(map[string]int, len(videoSources))
+
+	for i := range channels {
+		index, exists := batchIndexes[channels[i].Source]
+
+		if !exists {
+			index = len(batches)
+			batchIndexes[channels[i].Source] = index
+			batches = append(batches, videoSourceBatch{source: channels[i].Source})
+		}
+
+		batches[index].channels = append(batches[index].channels, channels[i].ID)
+	}
+
+	task := func(batch videoSourceBatch) (Videos, error) {
+		return videoSources[batch.source].FetchChannelUploads(batch.channels, options)
+	}
+
+	job := newJob(task, batches).withWorkers(len(batches))
+	results, errs, err := workerPoolDo(job)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoContent, err)
+	}
+
+	videos := make(Videos, 0, len(channels)*15)
+	var failed, partial int
+
+	for i := range results {
+		if errs[i] != nil {
+			if !errors.Is(errs[i], ErrPartialContent) {
+				failed++
+				slog.Error("Failed to fetch videos", "source", batches[i].source, "error", errs[i])
+				continue
+			}
+
+			partial++
+			slog.Warn("Failed to fetch some videos", "source", batches[i].source, "error", errs[i])
+		}
+
+		for j := range results[i] {
+			if options.keepsVideo(&results[i][j]) {
+				videos = append(videos, results[i][j])
+			}
+		}
+	}
+
+	if len(videos) == 0 {
+		return nil, ErrNoContent
+	}
+
+	videos.SortByNewest()
+
+	if failed > 0 || partial > 0 {
+		return videos, fmt.Errorf("%w: missing videos from %d sources", ErrPartialContent, failed+partial)
+	}
+
+	return videos, nil
+}
+
+type VideoMixOptions struct {
+	Limit int
+	// zero for no limit
+	PerChannelLimit int
+	// how many of each channel's newest videos are shown regardless of how
+	// old they are compared to the videos of other channels
+	MinPerChannel int
+	// take one video from each channel in turn instead of the newest overall
+	RoundRobin bool
+}
+
+func (v *Video) channelKey() string {
+	if v.AuthorUrl != "" {
+		return v.AuthorUrl
+	}
+
+	return v.Author
+}
+
+// Groups the videos by channel, keeping the order of the videos within each channel.
+// Channels are ordered by their first video
+func (v Videos) groupByChannel() []Videos {
+	channels := make([]Videos, 0, 16)
+	indexes := make(map[string]int)
+
+	for i := range v {
+		key := v[i].channelKey()
+		index, exists := indexes[key]
+
+		if !exists {
+			index = len(channels)
+			indexes[key] = index
+			channels = append(channels, nil)
+		}
+
+		channels[index] = append(channels[index], v[i])
+	}
+
+	return channels
+}
+
+// Picks which videos to show so that a few prolific channels can't crowd out
+// the others. Expects the videos to be sorted by newest
+func (v Videos) Mix(options VideoMixOptions) Videos {
+	channels := v.groupByChannel()
+
+	if options.PerChannelLimit > 0 {
+		for i := range channels {
+			if len(channels[i]) > options.PerChannelLimit {
+				channels[i] = channels[i][:options.PerChannelLimit]
+			}
+		}
+	}
+
+	limit := options.Limit
+
+	if limit <= 0 {
+		limit = len(v)
+	}
+
+	mixed := make(Videos, 0, min(limit, len(v)))
+
+	if options.RoundRobin {
+		for round := 0; len(mixed) < limit; round++ {
+			added := false
+
+			for i := range channels {
+				if round < len(channels[i]) && len(mixed) < limit {
+					mixed = append(mixed, channels[i][round])
+					added = true
+				}
+			}
+
+			if !added {
+				break
+			}
+		}
+
+		return mixed
+	}
+
+	// the guaranteed videos come first, the remaining slots go to the newest of the rest
+	remaining := make(Videos, 0, len(v))
+
+	for i := range channels {
+		guaranteed := min(options.MinPerChannel, len(channels[i]))
+		mixed = append(mixed, channels[i][:guaranteed]...)
+		remaining = append(remaining, channels[i][guaranteed:]...)
+	}
+
+	mixed.SortByNewest()
+
+	if len(mixed) > limit {
+		return mixed[:limit]
+	}
+
+	remaining.SortByNewest()
+
+	if free := limit - len(mixed); len(remaining) > free {
+		remaining = remaining[:free]
+	}
+
+	return append(mixed, remaining...).SortByNewest()
+}
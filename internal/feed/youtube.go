@@ -1,10 +1,12 @@
 package feed
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -14,6 +16,7 @@ type youtubeFeedResponseXml struct {
 	ChannelLink string `xml:"author>uri"`
 	Videos      []struct {
 		Title     string `xml:"title"`
+		VideoId   string `xml:"http://www.youtube.com/xml/schemas/2015 videoId"`
 		Published string `xml:"published"`
 		Link      struct {
 			Href string `xml:"href,attr"`
@@ -23,20 +26,39 @@ type youtubeFeedResponseXml struct {
 			Thumbnail struct {
 				Url string `xml:"url,attr"`
 			} `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+			Community struct {
+				Statistics struct {
+					// missing from some entries, which isn't the same as zero views
+					Views string `xml:"views,attr"`
+				} `xml:"http://search.yahoo.com/mrss/ statistics"`
+			} `xml:"http://search.yahoo.com/mrss/ community"`
 		} `xml:"http://search.yahoo.com/mrss/ group"`
 	} `xml:"entry"`
 }
 
 func parseYoutubeFeedTime(t string) time.Time {
-	parsedTime, err := time.Parse("2006-01-02T15:04:05-07:00", t)
+	parsedTime, err := ParseTime(t, nil)
 
 	if err != nil {
+		slog.Warn("failed to parse youtube feed time", "value", t, "error", err)
 		return time.Now()
 	}
 
 	return parsedTime
 }
 
+// The feeds don't say whether a video is a premiere or a scheduled stream, but
+// those are listed ahead of time without any views. Regular videos also have
+// none for a few minutes after being uploaded, so they're briefly treated the same
+func isYoutubeUpcoming(publishedAt time.Time, views string, now time.Time) bool {
+	return publishedAt.After(now) || views == "0"
+}
+
+// The feeds link to shorts through their own page rather than the watch page
+func isYoutubeShortLink(link string) bool {
+	return strings.Contains(link, "youtube.com/shorts/")
+}
+
 func FetchYoutubeChannelUploads(channelIds []string, videoUrlTemplate string, includeShorts bool) (Videos, error) {
 	requests := make([]*http.Request, 0, len(channelIds))
 
@@ -62,6 +84,7 @@ func FetchYoutubeChannelUploads(channelIds []string, videoUrlTemplate string, in
 	}
 
 	videos := make(Videos, 0, len(channelIds)*15)
+	now := time.Now()
 
 	var failed int
 
@@ -80,6 +103,8 @@ func FetchYoutubeChannelUploads(channelIds []string, videoUrlTemplate string, in
 
 			if videoUrlTemplate == "" {
 				videoUrl = video.Link.Href
+			} else if video.VideoId != "" {
+				videoUrl = strings.ReplaceAll(videoUrlTemplate, "{VIDEO-ID}", video.VideoId)
 			} else {
 				parsedUrl, err := url.Parse(video.Link.Href)
 
@@ -90,13 +115,19 @@ func FetchYoutubeChannelUploads(channelIds []string, videoUrlTemplate string, in
 				}
 			}
 
+			publishedAt := parseYoutubeFeedTime(video.Published)
+			views, _ := strconv.Atoi(video.Group.Community.Statistics.Views)
+
 			videos = append(videos, Video{
 				ThumbnailUrl: video.Group.Thumbnail.Url,
 				Title:        video.Title,
 				Url:          videoUrl,
 				Author:       response.Channel,
 				AuthorUrl:    response.ChannelLink + "/videos",
-				TimePosted:   parseYoutubeFeedTime(video.Published),
+				TimePosted:   publishedAt,
+				Views:        views,
+				IsUpcoming:   isYoutubeUpcoming(publishedAt, video.Group.Community.Statistics.Views, now),
+				IsShort:      isYoutubeShortLink(video.Link.Href),
 			})
 		}
 	}
@@ -113,3 +144,17 @@ func FetchYoutubeChannelUploads(channelIds []string, videoUrlTemplate string, in
 
 	return videos, nil
 }
+
+type youtubeVideoSource struct{}
+
+func (youtubeVideoSource) ParseChannel(channel string) (string, error) {
+	if channel == "" {
+		return "", errors.New("channel ID is empty")
+	}
+
+	return channel, nil
+}
+
+func (youtubeVideoSource) FetchChannelUploads(channels []string, options VideoFetchOptions) (Videos, error) {
+	return FetchYoutubeChannelUploads(channels, options.VideoUrlTemplate, options.IncludeShorts)
+}
@@ -0,0 +1,77 @@
+package glance
+
+import (
+	"fmt"
+
+	"github.com/glanceapp/glance/internal/feed"
+	"github.com/glanceapp/glance/internal/tool"
+	"github.com/glanceapp/glance/internal/widget"
+)
+
+// Configures the language model used by widgets to translate and summarize,
+// any server with an OpenAI compatible API works including local ones
+type AI struct {
+	BaseURL    widget.OptionalEnvString `yaml:"base-url"`
+	APIKey     widget.OptionalEnvString `yaml:"api-key"`
+	Model      string                   `yaml:"model"`
+	Translator string                   `yaml:"translator"`
+	Baidu      struct {
+		AppID  widget.OptionalEnvString `yaml:"app-id"`
+		AppKey widget.OptionalEnvString `yaml:"app-key"`
+	} `yaml:"baidu"`
+	translator tool.Translator
+	summarizer tool.Summarizer
+}
+
+// Translations made by one backend or model are kept separate from another's
+func (ai *AI) translatorBackend() string {
+	if ai.Translator == "baidu" {
+		return "baidu"
+	}
+
+	baseURL := ai.BaseURL.String()
+
+	if baseURL == "" {
+		baseURL = tool.DefaultOpenAIBaseURL
+	}
+
+	return "openai " + baseURL + " " + ai.Model
+}
+
+func (ai *AI) initialize(dataPath string) error {
+	if ai.Model != "" {
+		client, err := tool.NewOpenAIClient(ai.BaseURL.String(), ai.APIKey.String(), ai.Model)
+
+		if err != nil {
+			return err
+		}
+
+		ai.summarizer = client
+		ai.translator = client
+	} else if ai.BaseURL != "" || ai.APIKey != "" {
+		return fmt.Errorf("model is required when base-url or api-key are set")
+	}
+
+	switch ai.Translator {
+	case "", "openai":
+		if ai.Translator != "" && ai.translator == nil {
+			return fmt.Errorf("translator openai requires model to be set")
+		}
+	case "baidu":
+		translator, err := tool.NewBaiduTranslator(ai.Baidu.AppID.String(), ai.Baidu.AppKey.String())
+
+		if err != nil {
+			return fmt.Errorf("baidu: %v", err)
+		}
+
+		ai.translator = translator
+	default:
+		return fmt.Errorf("unknown translator %q, expected openai or baidu", ai.Translator)
+	}
+
+	if ai.translator != nil {
+		ai.translator = feed.NewCachedTranslator(ai.translator, ai.translatorBackend(), dataPath)
+	}
+
+	return nil
+}
@@ -11,6 +11,7 @@ type Config struct {
 	Server   Server   `yaml:"server"`
 	Theme    Theme    `yaml:"theme"`
 	Branding Branding `yaml:"branding"`
+	AI       AI       `yaml:"ai"`
 	Pages    []Page   `yaml:"pages"`
 }
 
@@ -29,14 +30,34 @@ func NewConfigFromYml(contents io.Reader) (*Config, error) {
 		return nil, err
 	}
 
+	for p := range config.Pages {
+		if err := config.Pages[p].initializeSections(); err != nil {
+			return nil, fmt.Errorf("Page %d: %v", p+1, err)
+		}
+	}
+
 	if err = configIsValid(config); err != nil {
 		return nil, err
 	}
 
+	if err = config.Theme.initialize(); err != nil {
+		return nil, fmt.Errorf("theme: %v", err)
+	}
+
+	if err = config.AI.initialize(config.Server.DataPath); err != nil {
+		return nil, fmt.Errorf("ai: %v", err)
+	}
+
 	for p := range config.Pages {
-		for c := range config.Pages[p].Columns {
-			for w := range config.Pages[p].Columns[c].Widgets {
-				if err := config.Pages[p].Columns[c].Widgets[w].Initialize(); err != nil {
+		if config.Pages[p].Theme != nil {
+			if err := config.Pages[p].Theme.initialize(); err != nil {
+				return nil, fmt.Errorf("Page %d theme: %v", p+1, err)
+			}
+		}
+
+		for _, column := range config.Pages[p].AllColumns() {
+			for w := range column.Widgets {
+				if err := column.Widgets[w].Initialize(); err != nil {
 					return nil, err
 				}
 			}
@@ -65,34 +86,18 @@ func configIsValid(config *Config) error {
 			return fmt.Errorf("Page %d: width can only be either wide or slim", i+1)
 		}
 
-		if len(config.Pages[i].Columns) == 0 {
+		if len(config.Pages[i].Sections) == 0 {
 			return fmt.Errorf("Page %d has no columns", i+1)
 		}
 
-		if config.Pages[i].Width == "slim" {
-			if len(config.Pages[i].Columns) > 2 {
-				return fmt.Errorf("Page %d is slim and cannot have more than 2 columns", i+1)
-			}
-		} else {
-			if len(config.Pages[i].Columns) > 3 {
-				return fmt.Errorf("Page %d has more than 3 columns: %d", i+1, len(config.Pages[i].Columns))
-			}
-		}
-
-		columnSizesCount := make(map[string]int)
+		for s := range config.Pages[i].Sections {
+			if err := sectionIsValid(&config.Pages[i].Sections[s], config.Pages[i].Width); err != nil {
+				if len(config.Pages[i].Sections) == 1 {
+					return fmt.Errorf("Page %d %v", i+1, err)
+				}
 
-		for j := range config.Pages[i].Columns {
-			if config.Pages[i].Columns[j].Size != "small" && config.Pages[i].Columns[j].Size != "full" {
-				return fmt.Errorf("Column %d of page %d: size can only be either small or full", j+1, i+1)
+				return fmt.Errorf("Page %d section %d %v", i+1, s+1, err)
 			}
-
-			columnSizesCount[config.Pages[i].Columns[j].Size]++
-		}
-
-		full := columnSizesCount["full"]
-
-		if full > 2 || full == 0 {
-			return fmt.Errorf("Page %d must have either 1 or 2 full width columns", i+1)
 		}
 	}
 
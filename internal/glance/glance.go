@@ -27,26 +27,24 @@ type Application struct {
 	Config     Config
 	slugToPage map[string]*Page
 	widgetByID map[uint64]widget.Widget
+	// the page and top level widget that every widget belongs to
+	widgetOwners map[uint64]widgetOwner
 }
 
-type Theme struct {
-	BackgroundColor          *widget.HSLColorField `yaml:"background-color"`
-	PrimaryColor             *widget.HSLColorField `yaml:"primary-color"`
-	PositiveColor            *widget.HSLColorField `yaml:"positive-color"`
-	NegativeColor            *widget.HSLColorField `yaml:"negative-color"`
-	Light                    bool                  `yaml:"light"`
-	ContrastMultiplier       float32               `yaml:"contrast-multiplier"`
-	TextSaturationMultiplier float32               `yaml:"text-saturation-multiplier"`
-	CustomCSSFile            string                `yaml:"custom-css-file"`
+type widgetOwner struct {
+	page   *Page
+	widget widget.Widget
 }
 
 type Server struct {
-	Host       string    `yaml:"host"`
-	Port       uint16    `yaml:"port"`
-	AssetsPath string    `yaml:"assets-path"`
-	BaseURL    string    `yaml:"base-url"`
-	AssetsHash string    `yaml:"-"`
-	StartedAt  time.Time `yaml:"-"` // used in custom css file
+	Host          string    `yaml:"host"`
+	Port          uint16    `yaml:"port"`
+	AssetsPath    string    `yaml:"assets-path"`
+	TemplatesPath string    `yaml:"templates-path"`
+	DataPath      string    `yaml:"data-path"`
+	BaseURL       string    `yaml:"base-url"`
+	AssetsHash    string    `yaml:"-"`
+	StartedAt     time.Time `yaml:"-"` // used in custom css file
 }
 
 type Branding struct {
@@ -57,24 +55,24 @@ type Branding struct {
 	FaviconURL   string        `yaml:"favicon-url"`
 }
 
-type Column struct {
-	Size    string         `yaml:"size"`
-	Widgets widget.Widgets `yaml:"widgets"`
-}
-
 type templateData struct {
-	App  *Application
-	Page *Page
+	App   *Application
+	Page  *Page
+	Theme *Theme
+	State ThemeState
 }
 
 type Page struct {
-	Title                 string   `yaml:"name"`
-	Slug                  string   `yaml:"slug"`
-	Width                 string   `yaml:"width"`
-	ShowMobileHeader      bool     `yaml:"show-mobile-header"`
-	HideDesktopNavigation bool     `yaml:"hide-desktop-navigation"`
-	CenterVertically      bool     `yaml:"center-vertically"`
-	Columns               []Column `yaml:"columns"`
+	Title                 string       `yaml:"name"`
+	Slug                  string       `yaml:"slug"`
+	Width                 string       `yaml:"width"`
+	ShowMobileHeader      bool         `yaml:"show-mobile-header"`
+	HideDesktopNavigation bool         `yaml:"hide-desktop-navigation"`
+	CenterVertically      bool         `yaml:"center-vertically"`
+	Theme                 *Theme       `yaml:"theme"`
+	Columns               []Column     `yaml:"columns"`
+	Sections              []Section    `yaml:"sections"`
+	Breakpoints           []Breakpoint `yaml:"breakpoints"`
 	mu                    sync.Mutex
 }
 
@@ -82,25 +80,59 @@ func (p *Page) UpdateOutdatedWidgets() {
 	now := time.Now()
 
 	var wg sync.WaitGroup
+	var consumers []widget.Widget
 	context := context.Background()
 
-	for c := range p.Columns {
-		for w := range p.Columns[c].Widgets {
-			widget := p.Columns[c].Widgets[w]
+	for _, column := range p.AllColumns() {
+		for w := range column.Widgets {
+			pageWidget := column.Widgets[w]
+
+			if !pageWidget.RequiresUpdate(&now) {
+				continue
+			}
 
-			if !widget.RequiresUpdate(&now) {
+			if _, ok := pageWidget.(widget.PageWidgetsConsumer); ok {
+				consumers = append(consumers, pageWidget)
 				continue
 			}
 
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				widget.Update(context)
+				pageWidget.Update(context)
 			}()
 		}
 	}
 
 	wg.Wait()
+
+	// these use the content of the other widgets so they can only be updated
+	// once the rest of the page is done updating
+	for _, consumer := range consumers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			consumer.Update(context)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (p *Page) allWidgets() widget.Widgets {
+	var widgets widget.Widgets
+
+	for _, column := range p.AllColumns() {
+		for _, w := range column.Widgets {
+			widgets = append(widgets, w)
+
+			if container, ok := w.(widget.Container); ok {
+				widgets = append(widgets, container.GetWidgets()...)
+			}
+		}
+	}
+
+	return widgets
 }
 
 // TODO: fix, currently very simple, lots of uncovered edge cases
@@ -126,10 +158,17 @@ func NewApplication(config *Config) (*Application, error) {
 	}
 
 	app := &Application{
-		Version:    buildVersion,
-		Config:     *config,
-		slugToPage: make(map[string]*Page),
-		widgetByID: make(map[uint64]widget.Widget),
+		Version:      buildVersion,
+		Config:       *config,
+		slugToPage:   make(map[string]*Page),
+		widgetByID:   make(map[uint64]widget.Widget),
+		widgetOwners: make(map[uint64]widgetOwner),
+	}
+
+	if config.Server.TemplatesPath != "" {
+		if err := assets.UseTemplateOverrides(config.Server.TemplatesPath); err != nil {
+			return nil, err
+		}
 	}
 
 	app.Config.Server.AssetsHash = assets.PublicFSHash
@@ -137,6 +176,10 @@ func NewApplication(config *Config) (*Application, error) {
 
 	providers := &widget.Providers{
 		AssetResolver: app.AssetPath,
+		AssetsPath:    config.Server.AssetsPath,
+		DataPath:      config.Server.DataPath,
+		Translator:    config.AI.translator,
+		Summarizer:    config.AI.summarizer,
 	}
 
 	for p := range config.Pages {
@@ -146,14 +189,22 @@ func NewApplication(config *Config) (*Application, error) {
 
 		app.slugToPage[config.Pages[p].Slug] = &config.Pages[p]
 
-		for c := range config.Pages[p].Columns {
-			for w := range config.Pages[p].Columns[c].Widgets {
-				widget := config.Pages[p].Columns[c].Widgets[w]
-				app.widgetByID[widget.GetID()] = widget
+		for _, column := range config.Pages[p].AllColumns() {
+			for w := range column.Widgets {
+				widget := column.Widgets[w]
+				app.registerWidget(widget, widgetOwner{page: &config.Pages[p], widget: widget})
 
 				widget.SetProviders(providers)
 			}
 		}
+
+		pageWidgets := config.Pages[p].allWidgets()
+
+		for _, w := range pageWidgets {
+			if consumer, ok := w.(widget.PageWidgetsConsumer); ok {
+				consumer.SetPageWidgets(pageWidgets)
+			}
+		}
 	}
 
 	config = &app.Config
@@ -161,6 +212,20 @@ func NewApplication(config *Config) (*Application, error) {
 	config.Server.BaseURL = strings.TrimRight(config.Server.BaseURL, "/")
 	config.Theme.CustomCSSFile = app.TransformUserDefinedAssetPath(config.Theme.CustomCSSFile)
 
+	for p := range config.Pages {
+		pageTheme := config.Pages[p].Theme
+
+		if pageTheme == nil {
+			continue
+		}
+
+		if pageTheme.CustomCSSFile == "" {
+			pageTheme.CustomCSSFile = config.Theme.CustomCSSFile
+		} else {
+			pageTheme.CustomCSSFile = app.TransformUserDefinedAssetPath(pageTheme.CustomCSSFile)
+		}
+	}
+
 	if config.Branding.FaviconURL == "" {
 		config.Branding.FaviconURL = app.AssetPath("favicon.png")
 	} else {
@@ -172,6 +237,14 @@ func NewApplication(config *Config) (*Application, error) {
 	return app, nil
 }
 
+func (a *Application) ThemeForPage(page *Page) *Theme {
+	if page.Theme != nil {
+		return page.Theme
+	}
+
+	return &a.Config.Theme
+}
+
 func (a *Application) HandlePageRequest(w http.ResponseWriter, r *http.Request) {
 	page, exists := a.slugToPage[r.PathValue("page")]
 
@@ -180,9 +253,13 @@ func (a *Application) HandlePageRequest(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	theme := a.ThemeForPage(page)
+
 	pageData := templateData{
-		Page: page,
-		App:  a,
+		Page:  page,
+		App:   a,
+		Theme: theme,
+		State: theme.State(time.Now()),
 	}
 
 	var responseBytes bytes.Buffer
@@ -251,14 +328,64 @@ func (a *Application) HandleWidgetRequest(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	widget, exists := a.widgetByID[widgetID]
+	requested, exists := a.widgetByID[widgetID]
 
 	if !exists {
 		a.HandleNotFound(w, r)
 		return
 	}
 
-	widget.HandleRequest(w, r)
+	path := r.PathValue("path")
+
+	if source, ok := requested.(widget.SyndicationFeedSource); ok && (path == "feed.xml" || path == "feed.json") {
+		a.serveWidgetFeed(w, r, widgetID, source)
+		return
+	}
+
+	// containers update and render their lazily loaded widgets when they're
+	// opened, which like any other update has to happen under the page's lock
+	if _, ok := requested.(widget.Container); ok {
+		owner := a.widgetOwners[widgetID]
+		owner.page.mu.Lock()
+		defer owner.page.mu.Unlock()
+	}
+
+	requested.HandleRequest(w, r)
+}
+
+// Feeds are requested by readers rather than the page, so the widget may be
+// outdated if the page hasn't been loaded in a while
+func (a *Application) serveWidgetFeed(w http.ResponseWriter, r *http.Request, widgetID uint64, source widget.SyndicationFeedSource) {
+	owner := a.widgetOwners[widgetID]
+	owner.page.mu.Lock()
+	now := time.Now()
+
+	if owner.widget.RequiresUpdate(&now) {
+		owner.widget.Update(context.Background())
+	}
+
+	// containers skip the widgets in tabs that haven't been opened, whose
+	// feeds would otherwise stay empty until someone opens them
+	if requested := a.widgetByID[widgetID]; requested != owner.widget && requested.RequiresUpdate(&now) {
+		requested.Update(context.Background())
+	}
+
+	items := source.SyndicationItems()
+	owner.page.mu.Unlock()
+
+	source.ServeSyndicationFeed(w, r, items)
+}
+
+// Widgets within containers also need to be reachable through the widget API
+func (a *Application) registerWidget(w widget.Widget, owner widgetOwner) {
+	a.widgetByID[w.GetID()] = w
+	a.widgetOwners[w.GetID()] = owner
+
+	if container, ok := w.(widget.Container); ok {
+		for _, child := range container.GetWidgets() {
+			a.registerWidget(child, owner)
+		}
+	}
 }
 
 func (a *Application) AssetPath(asset string) string {
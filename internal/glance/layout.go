@@ -0,0 +1,193 @@
+package glance
+
+import (
+	"fmt"
+	"html/template"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/glanceapp/glance/internal/widget"
+)
+
+// Below this width the page switches to the mobile layout where only one column
+// is visible at a time, see main.css
+const mobileLayoutMaxWidth = 1190
+
+var columnWidthPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)(fr|px|%)?$`)
+
+type Column struct {
+	Size    string         `yaml:"size"`
+	Width   string         `yaml:"width"`
+	Widgets widget.Widgets `yaml:"widgets"`
+	Index   int            `yaml:"-"`
+}
+
+// How many columns to place on each row once the viewport becomes narrower
+// than MaxWidth, columns that don't fit wrap onto the next row
+type Breakpoint struct {
+	MaxWidth int `yaml:"max-width"`
+	Columns  int `yaml:"columns"`
+}
+
+// A horizontal band of columns, pages that only specify columns get a single section
+type Section struct {
+	Columns     []Column     `yaml:"columns"`
+	Breakpoints []Breakpoint `yaml:"breakpoints"`
+}
+
+func (c *Column) gridTrack() string {
+	if c.Width == "" {
+		if c.Size == "small" {
+			return "300px"
+		}
+
+		return "minmax(0, 1fr)"
+	}
+
+	matches := columnWidthPattern.FindStringSubmatch(c.Width)
+
+	if matches[2] == "" || matches[2] == "fr" {
+		return "minmax(0, " + matches[1] + "fr)"
+	}
+
+	return matches[1] + matches[2]
+}
+
+// Sections without widths or breakpoints keep using the original flex based layout
+func (s *Section) IsGrid() bool {
+	if len(s.Breakpoints) > 0 {
+		return true
+	}
+
+	for i := range s.Columns {
+		if s.Columns[i].Width != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Section) GridTemplate() template.CSS {
+	tracks := make([]string, len(s.Columns))
+
+	for i := range s.Columns {
+		tracks[i] = s.Columns[i].gridTrack()
+	}
+
+	return template.CSS(strings.Join(tracks, " "))
+}
+
+func (b Breakpoint) GridTemplate() template.CSS {
+	return template.CSS(fmt.Sprintf("repeat(%d, minmax(0, 1fr))", b.Columns))
+}
+
+func (p *Page) AllColumns() []*Column {
+	columns := make([]*Column, 0, 3)
+
+	for s := range p.Sections {
+		for c := range p.Sections[s].Columns {
+			columns = append(columns, &p.Sections[s].Columns[c])
+		}
+	}
+
+	return columns
+}
+
+func (p *Page) initializeSections() error {
+	if len(p.Columns) > 0 && len(p.Sections) > 0 {
+		return fmt.Errorf("columns and sections cannot both be specified, move the columns into a section")
+	}
+
+	if len(p.Columns) > 0 {
+		p.Sections = []Section{{Columns: p.Columns}}
+		p.Columns = nil
+	}
+
+	index := 0
+
+	for s := range p.Sections {
+		section := &p.Sections[s]
+
+		if len(section.Breakpoints) == 0 {
+			section.Breakpoints = append(section.Breakpoints, p.Breakpoints...)
+		}
+
+		// later media queries take precedence so the narrowest breakpoints go last
+		sort.SliceStable(section.Breakpoints, func(i, j int) bool {
+			return section.Breakpoints[i].MaxWidth > section.Breakpoints[j].MaxWidth
+		})
+
+		for c := range section.Columns {
+			section.Columns[c].Index = index
+			index++
+
+			if section.Columns[c].Size == "" && section.Columns[c].Width != "" {
+				section.Columns[c].Size = "full"
+			}
+		}
+	}
+
+	return nil
+}
+
+func sectionIsValid(section *Section, pageWidth string) error {
+	if len(section.Columns) == 0 {
+		return fmt.Errorf("has no columns")
+	}
+
+	for i := range section.Breakpoints {
+		if section.Breakpoints[i].Columns < 1 {
+			return fmt.Errorf("breakpoint %d must have at least 1 column", i+1)
+		}
+
+		if section.Breakpoints[i].MaxWidth <= mobileLayoutMaxWidth {
+			return fmt.Errorf("breakpoint %d must have a max-width above %dpx since narrower screens use the mobile layout", i+1, mobileLayoutMaxWidth)
+		}
+	}
+
+	for j := range section.Columns {
+		column := &section.Columns[j]
+
+		if column.Width != "" {
+			matches := columnWidthPattern.FindStringSubmatch(column.Width)
+
+			if matches == nil {
+				return fmt.Errorf("column %d: invalid width %s, must be a number optionally followed by fr, px or %%", j+1, column.Width)
+			}
+		}
+
+		if column.Size != "small" && column.Size != "full" {
+			return fmt.Errorf("column %d: size can only be either small or full", j+1)
+		}
+	}
+
+	if section.IsGrid() {
+		return nil
+	}
+
+	if pageWidth == "slim" {
+		if len(section.Columns) > 2 {
+			return fmt.Errorf("is slim and cannot have more than 2 columns, use width on the columns for more")
+		}
+	} else {
+		if len(section.Columns) > 3 {
+			return fmt.Errorf("has more than 3 columns: %d, use width on the columns for more", len(section.Columns))
+		}
+	}
+
+	columnSizesCount := make(map[string]int)
+
+	for j := range section.Columns {
+		columnSizesCount[section.Columns[j].Size]++
+	}
+
+	full := columnSizesCount["full"]
+
+	if full > 2 || full == 0 {
+		return fmt.Errorf("must have either 1 or 2 full width columns")
+	}
+
+	return nil
+}
@@ -0,0 +1,266 @@
+package glance
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glanceapp/glance/internal/feed"
+	"github.com/glanceapp/glance/internal/widget"
+)
+
+const (
+	themePaletteDay   = "day"
+	themePaletteNight = "night"
+)
+
+type ThemePalette struct {
+	BackgroundColor          *widget.HSLColorField `yaml:"background-color"`
+	PrimaryColor             *widget.HSLColorField `yaml:"primary-color"`
+	PositiveColor            *widget.HSLColorField `yaml:"positive-color"`
+	NegativeColor            *widget.HSLColorField `yaml:"negative-color"`
+	Light                    bool                  `yaml:"light"`
+	ContrastMultiplier       float32               `yaml:"contrast-multiplier"`
+	TextSaturationMultiplier float32               `yaml:"text-saturation-multiplier"`
+}
+
+type Theme struct {
+	ThemePalette  `yaml:",inline"`
+	CustomCSSFile string         `yaml:"custom-css-file"`
+	Schedule      *ThemeSchedule `yaml:"schedule"`
+}
+
+// Switches between the day and night palettes either at fixed times of the
+// day or, when a location is specified, at sunrise and sunset for that location
+type ThemeSchedule struct {
+	Day           ThemePalette `yaml:"day"`
+	Night         ThemePalette `yaml:"night"`
+	DayStartsAt   string       `yaml:"day-starts-at"`
+	NightStartsAt string       `yaml:"night-starts-at"`
+	Location      string       `yaml:"location"`
+	dayStartsAt   time.Duration
+	nightStartsAt time.Duration
+	place         *feed.PlaceJson
+	timezone      *time.Location
+	nextLookup    time.Time
+	mu            sync.Mutex
+}
+
+type ThemeSwitch struct {
+	Palette string
+	At      time.Time
+}
+
+// The palette that is currently active along with the upcoming switches,
+// used by the page to keep the theme up to date without having to reload
+type ThemeState struct {
+	Name     string
+	Palette  *ThemePalette
+	Switches []ThemeSwitch
+}
+
+func (s ThemeState) SwitchesAsString() string {
+	switches := make([]string, 0, len(s.Switches))
+
+	for i := range s.Switches {
+		switches = append(switches, s.Switches[i].Palette+":"+strconv.FormatInt(s.Switches[i].At.Unix(), 10))
+	}
+
+	return strings.Join(switches, " ")
+}
+
+func (s *ThemeSchedule) initialize() error {
+	if s.DayStartsAt == "" {
+		s.DayStartsAt = "06:00"
+	}
+
+	if s.NightStartsAt == "" {
+		s.NightStartsAt = "18:00"
+	}
+
+	var err error
+
+	if s.dayStartsAt, err = widget.ParseTimeOfDay(s.DayStartsAt); err != nil {
+		return fmt.Errorf("day-starts-at: %v", err)
+	}
+
+	if s.nightStartsAt, err = widget.ParseTimeOfDay(s.NightStartsAt); err != nil {
+		return fmt.Errorf("night-starts-at: %v", err)
+	}
+
+	if s.dayStartsAt == s.nightStartsAt {
+		return fmt.Errorf("day-starts-at and night-starts-at cannot be the same")
+	}
+
+	return nil
+}
+
+func (t *Theme) initialize() error {
+	if t.Schedule == nil {
+		return nil
+	}
+
+	return t.Schedule.initialize()
+}
+
+func (t *Theme) State(now time.Time) ThemeState {
+	if t.Schedule == nil {
+		return ThemeState{Palette: &t.ThemePalette}
+	}
+
+	return t.Schedule.state(now)
+}
+
+// Looks up the place lazily since it requires a network request, if the lookup
+// fails the fixed times are used until the next attempt
+func (s *ThemeSchedule) resolveLocation() (*feed.PlaceJson, *time.Location) {
+	if s.Location == "" {
+		return nil, time.Local
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.place != nil || time.Now().Before(s.nextLookup) {
+		return s.place, s.timezone
+	}
+
+	place, err := feed.FetchPlaceFromName(s.Location)
+
+	if err != nil {
+		slog.Error("failed to look up theme schedule location", "location", s.Location, "error", err)
+		s.nextLookup = time.Now().Add(5 * time.Minute)
+		return nil, time.Local
+	}
+
+	timezone, err := time.LoadLocation(place.Timezone)
+
+	if err != nil {
+		timezone = time.Local
+	}
+
+	s.place = place
+	s.timezone = timezone
+
+	return s.place, s.timezone
+}
+
+func (s *ThemeSchedule) switchesForDate(place *feed.PlaceJson, year int, month time.Month, day int, location *time.Location) []ThemeSwitch {
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, location)
+
+	if place == nil {
+		return []ThemeSwitch{
+			{Palette: themePaletteDay, At: midnight.Add(s.dayStartsAt)},
+			{Palette: themePaletteNight, At: midnight.Add(s.nightStartsAt)},
+		}
+	}
+
+	sunrise, sunset, polar := sunriseAndSunset(midnight.Add(12*time.Hour), place.Latitude, place.Longitude)
+
+	switch polar {
+	case polarDay:
+		return []ThemeSwitch{{Palette: themePaletteDay, At: midnight}}
+	case polarNight:
+		return []ThemeSwitch{{Palette: themePaletteNight, At: midnight}}
+	}
+
+	return []ThemeSwitch{
+		{Palette: themePaletteDay, At: sunrise},
+		{Palette: themePaletteNight, At: sunset},
+	}
+}
+
+func (s *ThemeSchedule) state(now time.Time) ThemeState {
+	place, location := s.resolveLocation()
+	now = now.In(location)
+
+	switches := make([]ThemeSwitch, 0, 8)
+
+	for offset := -1; offset <= 2; offset++ {
+		date := now.AddDate(0, 0, offset)
+		switches = append(switches, s.switchesForDate(place, date.Year(), date.Month(), date.Day(), location)...)
+	}
+
+	sort.Slice(switches, func(i, j int) bool {
+		return switches[i].At.Before(switches[j].At)
+	})
+
+	state := ThemeState{Name: themePaletteNight}
+	upcomingUntil := now.Add(48 * time.Hour)
+
+	for i := range switches {
+		if !switches[i].At.After(now) {
+			state.Name = switches[i].Palette
+			continue
+		}
+
+		if switches[i].At.Before(upcomingUntil) {
+			state.Switches = append(state.Switches, switches[i])
+		}
+	}
+
+	if state.Name == themePaletteDay {
+		state.Palette = &s.Day
+	} else {
+		state.Palette = &s.Night
+	}
+
+	return state
+}
+
+type polarState int
+
+const (
+	polarNone polarState = iota
+	polarDay
+	polarNight
+)
+
+const (
+	julianUnixEpoch  = 2440587.5
+	julianEpoch2000  = 2451545.0
+	earthAxialTilt   = 23.4397
+	sunsetElevation  = -0.833
+	degreesToRadians = math.Pi / 180
+)
+
+func julianDateToTime(julian float64) time.Time {
+	return time.Unix(int64(math.Round((julian-julianUnixEpoch)*86400)), 0)
+}
+
+// Sunrise equation as described on https://en.wikipedia.org/wiki/Sunrise_equation,
+// accurate to within a minute or two which is more than enough for switching themes
+func sunriseAndSunset(noon time.Time, latitude, longitude float64) (time.Time, time.Time, polarState) {
+	julianDate := float64(noon.Unix())/86400 + julianUnixEpoch
+	julianDay := math.Round(julianDate - julianEpoch2000 + longitude/360)
+	meanSolarTime := julianDay - longitude/360
+
+	meanAnomaly := math.Mod(357.5291+0.98560028*meanSolarTime, 360)
+	meanAnomalyRad := meanAnomaly * degreesToRadians
+	center := 1.9148*math.Sin(meanAnomalyRad) + 0.02*math.Sin(2*meanAnomalyRad) + 0.0003*math.Sin(3*meanAnomalyRad)
+	eclipticLongitude := math.Mod(meanAnomaly+center+180+102.9372, 360) * degreesToRadians
+
+	transit := julianEpoch2000 + meanSolarTime + 0.0053*math.Sin(meanAnomalyRad) - 0.0069*math.Sin(2*eclipticLongitude)
+	declination := math.Asin(math.Sin(eclipticLongitude) * math.Sin(earthAxialTilt*degreesToRadians))
+	latitudeRad := latitude * degreesToRadians
+
+	hourAngleCos := (math.Sin(sunsetElevation*degreesToRadians) - math.Sin(latitudeRad)*math.Sin(declination)) /
+		(math.Cos(latitudeRad) * math.Cos(declination))
+
+	if hourAngleCos > 1 {
+		return time.Time{}, time.Time{}, polarNight
+	}
+
+	if hourAngleCos < -1 {
+		return time.Time{}, time.Time{}, polarDay
+	}
+
+	hourAngle := math.Acos(hourAngleCos) / degreesToRadians
+
+	return julianDateToTime(transit - hourAngle/360), julianDateToTime(transit + hourAngle/360), polarNone
+}
@@ -0,0 +1,265 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const browserPollInterval = 250 * time.Millisecond
+
+// BrowserRenderer 通过 Chrome DevTools Protocol 让已经运行的浏览器打开页面，
+// 例如使用 --remote-debugging-port=9222 启动的 Chrome 或 Chromium。
+// 每个页面都在新的标签页中打开，渲染完成后关闭
+type BrowserRenderer struct {
+	limiter  renderLimiter
+	endpoint string
+	client   *http.Client
+}
+
+func NewBrowserRenderer(endpoint string, options RenderOptions) (*BrowserRenderer, error) {
+	parsed, err := url.Parse(endpoint)
+
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid browser endpoint %q, expected something like http://127.0.0.1:9222", endpoint)
+	}
+
+	return &BrowserRenderer{
+		limiter:  newRenderLimiter(options),
+		endpoint: strings.TrimRight(endpoint, "/"),
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+type browserTarget struct {
+	ID                   string `json:"id"`
+	WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+}
+
+func (r *BrowserRenderer) openTarget(ctx context.Context) (*browserTarget, error) {
+	// 较新的 Chrome 只接受 PUT，旧版本只接受 GET
+	for _, method := range []string{http.MethodPut, http.MethodGet} {
+		request, err := http.NewRequestWithContext(ctx, method, r.endpoint+"/json/new?about:blank", nil)
+
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := r.client.Do(request)
+
+		if err != nil {
+			return nil, fmt.Errorf("opening a browser tab: %v", err)
+		}
+
+		if response.StatusCode == http.StatusMethodNotAllowed {
+			response.Body.Close()
+			continue
+		}
+
+		var target browserTarget
+		err = json.NewDecoder(response.Body).Decode(&target)
+		response.Body.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("opening a browser tab: %v", err)
+		}
+
+		if target.ID == "" || target.WebSocketDebuggerURL == "" {
+			return nil, errors.New("opening a browser tab: browser did not return a debugger URL")
+		}
+
+		return &target, nil
+	}
+
+	return nil, errors.New("opening a browser tab: browser rejected the request")
+}
+
+func (r *BrowserRenderer) closeTarget(id string) {
+	// 渲染所用的 context 此时可能已经超时，关闭标签页需要单独的 context
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, r.endpoint+"/json/close/"+id, nil)
+
+	if err != nil {
+		return
+	}
+
+	if response, err := r.client.Do(request); err == nil {
+		response.Body.Close()
+	}
+}
+
+type devtoolsMessage struct {
+	ID     int             `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params any             `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+type devtoolsSession struct {
+	conn   *websocket.Conn
+	nextID int
+}
+
+func (s *devtoolsSession) call(method string, params any, result any) error {
+	s.nextID++
+	id := s.nextID
+
+	if err := websocket.JSON.Send(s.conn, devtoolsMessage{ID: id, Method: method, Params: params}); err != nil {
+		return fmt.Errorf("%s: %v", method, err)
+	}
+
+	// 未启用任何事件，但仍然跳过 ID 不匹配的消息
+	for {
+		var message devtoolsMessage
+
+		if err := websocket.JSON.Receive(s.conn, &message); err != nil {
+			return fmt.Errorf("%s: %v", method, err)
+		}
+
+		if message.ID != id {
+			continue
+		}
+
+		if message.Error != nil {
+			return fmt.Errorf("%s: %s", method, message.Error.Message)
+		}
+
+		if result == nil {
+			return nil
+		}
+
+		return json.Unmarshal(message.Result, result)
+	}
+}
+
+func (s *devtoolsSession) evaluate(expression string, value any) error {
+	var response struct {
+		Result struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text string `json:"text"`
+		} `json:"exceptionDetails"`
+	}
+
+	err := s.call("Runtime.evaluate", map[string]any{
+		"expression":    expression,
+		"returnByValue": true,
+	}, &response)
+
+	if err != nil {
+		return err
+	}
+
+	if response.ExceptionDetails != nil {
+		return fmt.Errorf("evaluating script: %s", response.ExceptionDetails.Text)
+	}
+
+	return json.Unmarshal(response.Result.Value, value)
+}
+
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *BrowserRenderer) Render(ctx context.Context, pageUrl string) (*Page, error) {
+	ctx, release, err := r.limiter.acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	target, err := r.openTarget(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.closeTarget(target.ID)
+
+	config, err := websocket.NewConfig(target.WebSocketDebuggerURL, r.endpoint)
+
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := config.DialContext(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("connecting to browser: %v", err)
+	}
+
+	defer conn.Close()
+
+	// 超时后让阻塞中的读写立即返回
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	session := &devtoolsSession{conn: conn}
+
+	var navigation struct {
+		ErrorText string `json:"errorText"`
+	}
+
+	if err := session.call("Page.navigate", map[string]string{"url": pageUrl}, &navigation); err != nil {
+		return nil, err
+	}
+
+	if navigation.ErrorText != "" {
+		return nil, fmt.Errorf("navigating to %s: %s", pageUrl, navigation.ErrorText)
+	}
+
+	// 导航提交前 about:blank 仍然是当前文档，所以同时检查地址
+	for {
+		var loaded bool
+
+		if err := session.evaluate(`document.readyState === "complete" && location.href !== "about:blank"`, &loaded); err != nil {
+			return nil, err
+		}
+
+		if loaded {
+			break
+		}
+
+		if err := sleepContext(ctx, browserPollInterval); err != nil {
+			return nil, fmt.Errorf("waiting for %s to load: %v", pageUrl, err)
+		}
+	}
+
+	if r.limiter.options.Wait > 0 {
+		if err := sleepContext(ctx, r.limiter.options.Wait); err != nil {
+			return nil, fmt.Errorf("waiting for %s to render: %v", pageUrl, err)
+		}
+	}
+
+	var result [2]string
+
+	if err := session.evaluate(`[location.href, document.documentElement.outerHTML]`, &result); err != nil {
+		return nil, err
+	}
+
+	return &Page{URL: result[0], HTML: result[1]}, nil
+}
@@ -7,80 +7,107 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
-	"time"
 )
 
-// FetchOptions 定义调用 fetcher.exe 所需的参数
-type FetchOptions struct {
-	URL         string
-	Timeout     int
-	Show        bool
-	Wait        string
-	Render      int
-	UserDataDir string
-	OutputDir   string
-	CookieFile  string
-	FetcherPath string // 新增字段，用于指定 fetcher.exe 的路径
+// CommandRenderer 调用外部程序渲染页面，例如 fetcher.exe。参数中的占位符会被替换：
+//
+//	{URL}     页面地址
+//	{TIMEOUT} 超时时间，单位为秒
+//	{WAIT}    加载完成后额外等待的时间，单位为毫秒
+//	{OUTPUT}  临时文件的路径，程序应将 HTML 写入其中
+//
+// 参数中不包含 {OUTPUT} 时，程序的标准输出即为 HTML
+type CommandRenderer struct {
+	limiter renderLimiter
+	command string
+	args    []string
 }
 
-// FetchHTML 调用 fetcher.exe 并返回抓取到的 HTML 内容，支持上下文超时
-func FetchHTML(options FetchOptions) (string, error) {
-	// 构建命令参数
-	args := []string{
-		options.URL,
-		"--timeout", fmt.Sprintf("%d", options.Timeout),
-		"--wait", options.Wait,
-		"--render", fmt.Sprintf("%d", options.Render),
-		"--user-data-dir", options.UserDataDir,
-		"--output", options.OutputDir,
+func NewCommandRenderer(command string, args []string, options RenderOptions) (*CommandRenderer, error) {
+	if command == "" {
+		return nil, errors.New("command is required")
 	}
 
-	if options.Show {
-		args = append(args, "--show")
+	if len(args) == 0 {
+		args = []string{"{URL}"}
 	}
-	if options.CookieFile != "" {
-		args = append(args, "--cookie-file", options.CookieFile)
+
+	return &CommandRenderer{
+		limiter: newRenderLimiter(options),
+		command: command,
+		args:    args,
+	}, nil
+}
+
+func (r *CommandRenderer) Render(ctx context.Context, url string) (*Page, error) {
+	ctx, release, err := r.limiter.acquire(ctx)
+
+	if err != nil {
+		return nil, err
 	}
 
-	// 确定 fetcher.exe 的路径
-	fetcherPath := "fetcher.exe" // 默认在 PATH 中
-	if options.FetcherPath != "" {
-		fetcherPath = options.FetcherPath
+	defer release()
+
+	var outputPath string
+
+	for i := range r.args {
+		if strings.Contains(r.args[i], "{OUTPUT}") {
+			file, err := os.CreateTemp("", "glance-render-*.html")
+
+			if err != nil {
+				return nil, fmt.Errorf("creating output file: %v", err)
+			}
+
+			outputPath = file.Name()
+			file.Close()
+			defer os.Remove(outputPath)
+
+			break
+		}
 	}
 
-	// 创建带有超时的上下文
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(options.Timeout+5)*time.Second)
-	defer cancel()
+	replacer := strings.NewReplacer(
+		"{URL}", url,
+		"{TIMEOUT}", strconv.Itoa(int(r.limiter.options.Timeout.Seconds())),
+		"{WAIT}", strconv.FormatInt(r.limiter.options.Wait.Milliseconds(), 10),
+		"{OUTPUT}", outputPath,
+	)
 
-	// 创建命令
-	cmd := exec.CommandContext(ctx, fetcherPath, args...)
+	args := make([]string, len(r.args))
+
+	for i := range r.args {
+		args[i] = replacer.Replace(r.args[i])
+	}
+
+	cmd := exec.CommandContext(ctx, r.command, args...)
 
-	// 捕获 stdout 和 stderr
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	// 运行命令
-	err := cmd.Run()
-	if ctx.Err() == context.DeadlineExceeded {
-		return "", fmt.Errorf("执行 fetcher.exe 超时")
-	}
-	if err != nil {
-		return "", fmt.Errorf("执行 fetcher.exe 失败: %v, Stderr: %s", err, stderr.String())
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("running %s: %w", r.command, ctx.Err())
+		}
+
+		return nil, fmt.Errorf("running %s: %v, stderr: %s", r.command, err, strings.TrimSpace(stderr.String()))
 	}
 
-	// 获取文件路径
-	filePath := strings.TrimSpace(stdout.String())
-	if filePath == "" {
-		return "", errors.New("fetcher.exe 未返回文件路径")
+	content := stdout.Bytes()
+
+	if outputPath != "" {
+		content, err = os.ReadFile(outputPath)
+
+		if err != nil {
+			return nil, fmt.Errorf("reading output of %s: %v", r.command, err)
+		}
 	}
 
-	// 读取文件内容
-	htmlContent, err := os.ReadFile(options.OutputDir)
-	if err != nil {
-		return "", fmt.Errorf("读取文件失败: %v", err)
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil, fmt.Errorf("%s returned an empty page", r.command)
 	}
 
-	return string(htmlContent), nil
+	return &Page{URL: url, HTML: string(content)}, nil
 }
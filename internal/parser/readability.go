@@ -0,0 +1,281 @@
+package parser
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Article 是从文章页面中提取出的正文
+type Article struct {
+	Title    string
+	Byline   string
+	SiteName string
+	// 正文部分未经过滤的 HTML，显示前需要清理
+	Content string
+	// 正文的字符数
+	Length int
+}
+
+var (
+	unlikelyCandidatesPattern = regexp.MustCompile(`(?i)-ad-|ai2html|banner|breadcrumbs|combx|comment|community|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|related|remark|replies|rss|shoutbox|sidebar|skyscraper|social|sponsor|supplemental|ad-break|agegate|pagination|pager|popup|yom-remote|share|subscribe|newsletter|recommend`)
+	maybeCandidatePattern     = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveClassPattern      = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|pagination|post|text|blog|story`)
+	negativeClassPattern      = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+)
+
+// 提取时直接丢弃的元素，包括其所有子节点
+var removedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+	atom.Object: true, atom.Embed: true, atom.Form: true, atom.Input: true,
+	atom.Button: true, atom.Select: true, atom.Textarea: true, atom.Svg: true,
+	atom.Nav: true, atom.Aside: true, atom.Footer: true, atom.Link: true,
+	atom.Meta: true, atom.Canvas: true, atom.Dialog: true, atom.Template: true,
+}
+
+// ExtractArticle 参考 Readability 的思路对段落打分，找出页面中最可能是正文的部分
+func ExtractArticle(doc *html.Node) (*Article, error) {
+	article := &Article{
+		Title:    metaContent(doc, "og:title"),
+		Byline:   metaContent(doc, "author"),
+		SiteName: metaContent(doc, "og:site_name"),
+	}
+
+	if article.Title == "" {
+		if title := htmlquery.FindOne(doc, "//title"); title != nil {
+			article.Title = strings.TrimSpace(htmlquery.InnerText(title))
+		}
+	}
+
+	body := htmlquery.FindOne(doc, "//body")
+
+	if body == nil {
+		return nil, errors.New("page has no body element")
+	}
+
+	removeUnlikelyNodes(body)
+
+	topCandidate := findTopCandidate(body)
+
+	if topCandidate == nil {
+		topCandidate = body
+	}
+
+	var builder strings.Builder
+
+	for _, node := range collectArticleNodes(topCandidate) {
+		if err := html.Render(&builder, node); err != nil {
+			return nil, err
+		}
+
+		article.Length += textLength(node)
+	}
+
+	article.Content = builder.String()
+
+	if article.Length == 0 {
+		return nil, errors.New("could not find the article content in the page")
+	}
+
+	return article, nil
+}
+
+func metaContent(doc *html.Node, name string) string {
+	node := htmlquery.FindOne(doc, `//meta[@property="`+name+`" or @name="`+name+`"]`)
+
+	if node == nil {
+		return ""
+	}
+
+	return strings.TrimSpace(htmlquery.SelectAttr(node, "content"))
+}
+
+func classAndID(node *html.Node) string {
+	return htmlquery.SelectAttr(node, "class") + " " + htmlquery.SelectAttr(node, "id")
+}
+
+func removeUnlikelyNodes(node *html.Node) {
+	for child := node.FirstChild; child != nil; {
+		next := child.NextSibling
+
+		switch child.Type {
+		case html.CommentNode:
+			node.RemoveChild(child)
+		case html.ElementNode:
+			matchString := classAndID(child)
+
+			if removedElements[child.DataAtom] ||
+				(child.DataAtom != atom.Article && child.DataAtom != atom.Main &&
+					unlikelyCandidatesPattern.MatchString(matchString) &&
+					!maybeCandidatePattern.MatchString(matchString)) {
+				node.RemoveChild(child)
+			} else {
+				removeUnlikelyNodes(child)
+			}
+		}
+
+		child = next
+	}
+}
+
+func classWeight(node *html.Node) float64 {
+	weight := 0.0
+
+	for _, value := range []string{htmlquery.SelectAttr(node, "class"), htmlquery.SelectAttr(node, "id")} {
+		if value == "" {
+			continue
+		}
+
+		if negativeClassPattern.MatchString(value) {
+			weight -= 25
+		}
+
+		if positiveClassPattern.MatchString(value) {
+			weight += 25
+		}
+	}
+
+	return weight
+}
+
+func initialScore(node *html.Node) float64 {
+	score := classWeight(node)
+
+	switch node.DataAtom {
+	case atom.Article:
+		score += 10
+	case atom.Div:
+		score += 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score += 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li:
+		score -= 3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score -= 5
+	}
+
+	return score
+}
+
+func textLength(node *html.Node) int {
+	return utf8.RuneCountInString(strings.TrimSpace(htmlquery.InnerText(node)))
+}
+
+// 链接文字占全部文字的比例，比例越高越可能是导航或推荐列表
+func linkDensity(node *html.Node) float64 {
+	length := textLength(node)
+
+	if length == 0 {
+		return 0
+	}
+
+	linkLength := 0
+
+	for _, link := range htmlquery.Find(node, ".//a") {
+		linkLength += textLength(link)
+	}
+
+	return float64(linkLength) / float64(length)
+}
+
+func findTopCandidate(body *html.Node) *html.Node {
+	scores := make(map[*html.Node]float64)
+	candidates := make([]*html.Node, 0, 16)
+
+	addScore := func(node *html.Node, score float64) {
+		if node == nil || node.Type != html.ElementNode {
+			return
+		}
+
+		if _, exists := scores[node]; !exists {
+			scores[node] = initialScore(node)
+			candidates = append(candidates, node)
+		}
+
+		scores[node] += score
+	}
+
+	for _, paragraph := range htmlquery.Find(body, ".//p | .//pre | .//td | .//blockquote") {
+		text := strings.TrimSpace(htmlquery.InnerText(paragraph))
+		length := utf8.RuneCountInString(text)
+
+		if length < 25 {
+			continue
+		}
+
+		// 中文标点同样计入逗号数量
+		score := 1 + float64(strings.Count(text, ",")+strings.Count(text, "，")+strings.Count(text, "、"))
+		score += math.Min(math.Floor(float64(length)/100), 3)
+
+		addScore(paragraph.Parent, score)
+
+		if paragraph.Parent != nil {
+			addScore(paragraph.Parent.Parent, score/2)
+		}
+	}
+
+	var top *html.Node
+	topScore := 0.0
+
+	for _, candidate := range candidates {
+		score := scores[candidate] * (1 - linkDensity(candidate))
+		scores[candidate] = score
+
+		if top == nil || score > topScore {
+			top = candidate
+			topScore = score
+		}
+	}
+
+	if top == nil {
+		return nil
+	}
+
+	// 正文常被拆分在相邻的多个元素中，得分足够高的兄弟节点一并保留
+	threshold := math.Max(10, topScore*0.2)
+	wrapper := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+
+	if top.Parent == nil {
+		return top
+	}
+
+	for sibling := top.Parent.FirstChild; sibling != nil; {
+		next := sibling.NextSibling
+		keep := sibling == top
+
+		if !keep && sibling.Type == html.ElementNode {
+			if score, exists := scores[sibling]; exists && score >= threshold {
+				keep = true
+			} else if sibling.DataAtom == atom.P {
+				length := textLength(sibling)
+				density := linkDensity(sibling)
+				keep = (length > 80 && density < 0.25) || (length > 0 && length <= 80 && density == 0 && strings.ContainsAny(htmlquery.InnerText(sibling), ".。"))
+			}
+		}
+
+		if keep {
+			sibling.Parent.RemoveChild(sibling)
+			wrapper.AppendChild(sibling)
+		}
+
+		sibling = next
+	}
+
+	return wrapper
+}
+
+func collectArticleNodes(candidate *html.Node) []*html.Node {
+	nodes := make([]*html.Node, 0, 8)
+
+	for child := candidate.FirstChild; child != nil; child = child.NextSibling {
+		nodes = append(nodes, child)
+	}
+
+	return nodes
+}
@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRenderTimeout       = 15 * time.Second
+	defaultRenderMaxConcurrent = 2
+	renderMaxBodySize          = 5 * 1024 * 1024
+	browserUserAgent           = "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:123.0) Gecko/20100101 Firefox/123.0"
+)
+
+// Page 是渲染完成后的页面
+type Page struct {
+	// 跳转之后的最终地址，页面中的相对链接以它为准
+	URL  string
+	HTML string
+}
+
+// Renderer 获取页面的 HTML，不同的实现决定页面中的 JavaScript 是否会被执行
+type Renderer interface {
+	Render(ctx context.Context, url string) (*Page, error)
+}
+
+type RenderOptions struct {
+	// 单个页面从开始请求到渲染完成的最长时间
+	Timeout time.Duration
+	// 页面加载完成后额外等待的时间，留给脚本填充内容
+	Wait time.Duration
+	// 同时渲染的页面数量上限
+	MaxConcurrent int
+}
+
+func (o *RenderOptions) setDefaults() {
+	if o.Timeout <= 0 {
+		o.Timeout = defaultRenderTimeout
+	}
+
+	if o.MaxConcurrent <= 0 {
+		o.MaxConcurrent = defaultRenderMaxConcurrent
+	}
+}
+
+// renderLimiter 限制同一个 Renderer 同时处理的页面数量，并为每次渲染加上超时
+type renderLimiter struct {
+	options RenderOptions
+	slots   chan struct{}
+}
+
+func newRenderLimiter(options RenderOptions) renderLimiter {
+	options.setDefaults()
+
+	return renderLimiter{
+		options: options,
+		slots:   make(chan struct{}, options.MaxConcurrent),
+	}
+}
+
+func (l *renderLimiter) acquire(ctx context.Context) (context.Context, func(), error) {
+	ctx, cancel := context.WithTimeout(ctx, l.options.Timeout)
+
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		cancel()
+		return nil, nil, fmt.Errorf("waiting to render: %w", ctx.Err())
+	}
+
+	return ctx, func() {
+		<-l.slots
+		cancel()
+	}, nil
+}
+
+// HTTPRenderer 直接请求页面，不执行其中的脚本
+type HTTPRenderer struct {
+	limiter renderLimiter
+	client  *http.Client
+}
+
+func NewHTTPRenderer(options RenderOptions) *HTTPRenderer {
+	return &HTTPRenderer{
+		limiter: newRenderLimiter(options),
+		client:  &http.Client{},
+	}
+}
+
+func (r *HTTPRenderer) Render(ctx context.Context, url string) (*Page, error) {
+	ctx, release, err := r.limiter.acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("User-Agent", browserUserAgent)
+	request.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	response, err := r.client.Do(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d for %s", response.StatusCode, url)
+	}
+
+	if contentType := response.Header.Get("Content-Type"); contentType != "" && !strings.Contains(contentType, "html") {
+		return nil, fmt.Errorf("unexpected content type %s for %s", contentType, url)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, renderMaxBodySize))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Page{URL: response.Request.URL.String(), HTML: string(body)}, nil
+}
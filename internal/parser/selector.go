@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+// Selector 是编译好的 XPath 或 CSS 选择器
+type Selector struct {
+	source string
+	xpath  *xpath.Expr
+	css    cascadia.Selector
+}
+
+// CompileSelector 编译选择器。以 xpath: 或 css: 开头时使用指定的语法，
+// 否则以 /、./ 或 ( 开头的视为 XPath，其余视为 CSS 选择器
+func CompileSelector(source string) (*Selector, error) {
+	source = strings.TrimSpace(source)
+	selector := &Selector{source: source}
+
+	if source == "" {
+		return nil, fmt.Errorf("selector is empty")
+	}
+
+	isXPath := strings.HasPrefix(source, "/") || strings.HasPrefix(source, "./") || strings.HasPrefix(source, "(")
+
+	if expression, found := strings.CutPrefix(source, "xpath:"); found {
+		source, isXPath = expression, true
+	} else if expression, found := strings.CutPrefix(source, "css:"); found {
+		source, isXPath = expression, false
+	}
+
+	var err error
+
+	if isXPath {
+		selector.xpath, err = xpath.Compile(source)
+	} else {
+		selector.css, err = cascadia.Compile(source)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %v", selector.source, err)
+	}
+
+	return selector, nil
+}
+
+func (s *Selector) String() string {
+	return s.source
+}
+
+// FindAll 返回 node 之下所有匹配的节点，XPath 选中属性时返回以属性值为文本的节点
+func (s *Selector) FindAll(node *html.Node) []*html.Node {
+	if s.xpath != nil {
+		return htmlquery.QuerySelectorAll(node, s.xpath)
+	}
+
+	return s.css.MatchAll(node)
+}
+
+func (s *Selector) FindOne(node *html.Node) *html.Node {
+	if s.xpath != nil {
+		return htmlquery.QuerySelector(node, s.xpath)
+	}
+
+	return s.css.MatchFirst(node)
+}
+
+// NodeValue 返回节点指定属性的值，未指定属性时返回去掉多余空白的文本
+func NodeValue(node *html.Node, attribute string) string {
+	if node == nil {
+		return ""
+	}
+
+	if attribute != "" {
+		return strings.TrimSpace(htmlquery.SelectAttr(node, attribute))
+	}
+
+	return strings.Join(strings.Fields(htmlquery.InnerText(node)), " ")
+}
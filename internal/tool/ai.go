@@ -0,0 +1,49 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// AutoDetectLanguage 表示由翻译服务自动识别源语言
+const AutoDetectLanguage = "auto"
+
+// Translator 将多段文本从 from 翻译为 to，语言使用 BCP 47 代码，例如 en、zh、zh-TW。
+// 返回的结果与 texts 一一对应，翻译失败的为空字符串，此时同时返回错误
+type Translator interface {
+	Translate(ctx context.Context, texts []string, from, to string) ([]string, error)
+}
+
+// Summarizer 使用指定的语言总结内容
+type Summarizer interface {
+	Summarize(ctx context.Context, content, language string) (string, error)
+}
+
+// ParseLanguage 校验语言代码并返回规范化后的形式
+func ParseLanguage(code string) (string, error) {
+	tag, err := language.Parse(code)
+
+	if err != nil {
+		return "", fmt.Errorf("invalid language %q", code)
+	}
+
+	return tag.String(), nil
+}
+
+// languageName 返回语言的英文名称，用于提示词，例如 zh 返回 Chinese
+func languageName(code string) string {
+	tag, err := language.Parse(code)
+
+	if err != nil {
+		return code
+	}
+
+	if name := display.English.Tags().Name(tag); name != "" {
+		return name
+	}
+
+	return code
+}
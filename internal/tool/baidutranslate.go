@@ -1,99 +1,198 @@
 package tool
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"math/rand"
 	"net/http"
 	"net/url"
-	"sync"
+	"strings"
 	"time"
 )
 
-const (
-	appID  = "20200208000381654"    // 固定 AppID
-	appKey = "FPTQJyuTh3BBvcbT8cby" // 固定 AppKey
-	apiURL = "http://api.fanyi.baidu.com/api/trans/vip/translate"
-)
+const baiduTranslateURL = "https://fanyi-api.baidu.com/api/trans/vip/translate"
+
+// 百度翻译使用自己的语言代码，未列出的直接使用 BCP 47 代码的语言部分
+var baiduLanguageCodes = map[string]string{
+	"zh-Hant": "cht",
+	"zh-TW":   "cht",
+	"zh-HK":   "cht",
+	"ja":      "jp",
+	"ko":      "kor",
+	"fr":      "fra",
+	"es":      "spa",
+	"ar":      "ara",
+	"bg":      "bul",
+	"et":      "est",
+	"da":      "dan",
+	"fi":      "fin",
+	"ro":      "rom",
+	"sl":      "slo",
+	"sv":      "swe",
+	"vi":      "vie",
+}
 
-var (
-	cache = make(map[string]string)
-	mu    sync.Mutex
-)
+// BaiduTranslator 使用百度翻译开放平台的通用翻译 API
+type BaiduTranslator struct {
+	appID  string
+	appKey string
+	client *http.Client
+}
+
+func NewBaiduTranslator(appID, appKey string) (*BaiduTranslator, error) {
+	if appID == "" || appKey == "" {
+		return nil, errors.New("app-id and app-key are required")
+	}
+
+	return &BaiduTranslator{
+		appID:  appID,
+		appKey: appKey,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
 
 type translateResponse struct {
 	TransResult []struct {
 		Dst string `json:"dst"`
 	} `json:"trans_result"`
-	ErrorMsg string `json:"error_msg,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"`
+	ErrorMsg  string `json:"error_msg,omitempty"`
 }
 
-// 对外暴露的翻译函数，设置默认语言
-func TranslateWithDefaults(query string) (string, error) {
-	return Translate(query, "en", "zh")
+func baiduLanguageCode(code string) string {
+	if code == "" || code == AutoDetectLanguage {
+		return AutoDetectLanguage
+	}
+
+	if mapped, ok := baiduLanguageCodes[code]; ok {
+		return mapped
+	}
+
+	base, _, _ := strings.Cut(code, "-")
+
+	if mapped, ok := baiduLanguageCodes[base]; ok {
+		return mapped
+	}
+
+	return base
 }
 
-// Translate translates the given text. Defaults to "en" (English) to "zh" (Simplified Chinese) if not specified.
-func Translate(query, fromLang, toLang string) (string, error) {
-	mu.Lock()
-	if result, found := cache[query]; found {
-		mu.Unlock()
-		return result, nil
+// 每次请求的文本不能超过 6000 字节，留出一些余量
+const baiduMaxQueryBytes = 5000
+
+// Translate 将多段文本按行合并后发送，百度按行返回结果，因此文本中的换行会被替换为空格
+func (t *BaiduTranslator) Translate(ctx context.Context, texts []string, fromLang, toLang string) ([]string, error) {
+	from, to := baiduLanguageCode(fromLang), baiduLanguageCode(toLang)
+	results := make([]string, len(texts))
+	failed := 0
+	var lastErr error
+
+	// 百度会丢弃空行导致行数对不上，只包含空白的文本不发送，结果保持为空
+	lines := make([]string, 0, len(texts))
+	indexes := make([]int, 0, len(texts))
+
+	for i := range texts {
+		if line := strings.Join(strings.Fields(texts[i]), " "); line != "" {
+			lines = append(lines, line)
+			indexes = append(indexes, i)
+		}
 	}
-	mu.Unlock()
 
-	// Generate salt and sign
-	rand.Seed(time.Now().UnixNano())
+	for start := 0; start < len(lines); {
+		end, size := start, 0
+
+		for end < len(lines) && (end == start || size+len(lines[end])+1 <= baiduMaxQueryBytes) {
+			size += len(lines[end]) + 1
+			end++
+		}
+
+		translated, err := t.translateLines(ctx, lines[start:end], from, to)
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return results, ctx.Err()
+			}
+
+			failed += end - start
+			lastErr = err
+		} else {
+			for i := range translated {
+				results[indexes[start+i]] = translated[i]
+			}
+		}
+
+		start = end
+	}
+
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d translations failed, last error: %v", failed, len(texts), lastErr)
+	}
+
+	return results, nil
+}
+
+func (t *BaiduTranslator) translateLines(ctx context.Context, lines []string, from, to string) ([]string, error) {
+	query := strings.Join(lines, "\n")
 	salt := rand.Intn(65536-32768) + 32768
-	sign := generateSign(query, salt)
 
-	// Build request
 	data := url.Values{}
-	data.Set("appid", appID)
+	data.Set("appid", t.appID)
 	data.Set("q", query)
-	data.Set("from", fromLang)
-	data.Set("to", toLang)
+	data.Set("from", from)
+	data.Set("to", to)
 	data.Set("salt", fmt.Sprintf("%d", salt))
-	data.Set("sign", sign)
+	data.Set("sign", t.generateSign(query, salt))
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, baiduTranslateURL, strings.NewReader(data.Encode()))
+
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := http.PostForm(apiURL, data)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.client.Do(request)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var result translateResponse
 	if err := json.Unmarshal(body, &result); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	if len(result.TransResult) > 0 {
-		finalResult := ""
-		for _, res := range result.TransResult {
-			finalResult += res.Dst
-		}
+	if len(result.TransResult) == 0 {
+		return nil, fmt.Errorf("translation failed: %s %s", result.ErrorCode, result.ErrorMsg)
+	}
+
+	// 空行不会出现在结果中，此时无法确定每行对应的结果
+	if len(result.TransResult) != len(lines) {
+		return nil, fmt.Errorf("translation returned %d lines instead of %d", len(result.TransResult), len(lines))
+	}
 
-		mu.Lock()
-		cache[query] = finalResult
-		mu.Unlock()
+	translated := make([]string, len(lines))
 
-		return finalResult, nil
+	for i, res := range result.TransResult {
+		translated[i] = res.Dst
 	}
 
-	return "", fmt.Errorf("translation failed: %s", result.ErrorMsg)
+	return translated, nil
 }
 
-// generateSign generates an MD5 hash for the translation request
-func generateSign(query string, salt int) string {
-	signStr := fmt.Sprintf("%s%s%d%s", appID, query, salt, appKey)
+// generateSign 生成请求签名，即 appid+q+salt+密钥 的 MD5
+func (t *BaiduTranslator) generateSign(query string, salt int) string {
+	signStr := fmt.Sprintf("%s%s%d%s", t.appID, query, salt, t.appKey)
 	hash := md5.Sum([]byte(signStr))
 	return hex.EncodeToString(hash[:])
 }
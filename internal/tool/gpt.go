@@ -2,48 +2,55 @@ package tool
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"sync"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
 
 	openai "github.com/sashabaranov/go-openai"
 )
 
-var (
-	client     *openai.Client
-	clientOnce sync.Once
-	initError  error
-)
-
-// 内置的 API 令牌和自定义域名
 const (
-	apiToken     = "sk-ia3AsF58W0KL3xlI51774aBe9d344118896393D6F4F55f6b" // 请替换为您的实际 API 令牌
-	customDomain = "https://api.wssh.one/v1"                             // 请替换为您的自定义域名
-	model        = "SparkDesk-v4.0"                                      // 使用的模型名称
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+	openAIRequestTimeout = 2 * time.Minute
 )
 
-// initializeClient 初始化 OpenAI 客户端（单例）
-func initializeClient() error {
-	clientOnce.Do(func() {
-		config := openai.DefaultConfig(apiToken)
-		config.BaseURL = customDomain
-		client = openai.NewClientWithConfig(config)
-
-	})
-	return initError
+// OpenAIClient 通过兼容 OpenAI 的接口完成翻译和总结，
+// 除了 OpenAI 本身也可以是 Ollama、LM Studio 等本地服务
+type OpenAIClient struct {
+	client *openai.Client
+	model  string
 }
 
-// PromptSummarize 接受内容字符串并返回中文总结
-func PromptSummarize(content string) (string, error) {
-	// 确保客户端已初始化
-	if err := initializeClient(); err != nil {
-		return "", err
+// NewOpenAIClient 创建客户端，本地服务通常不需要 apiKey
+func NewOpenAIClient(baseURL, apiKey, model string) (*OpenAIClient, error) {
+	if model == "" {
+		return nil, errors.New("model is required")
 	}
 
-	// 定义对话消息，包括系统角色和用户输入
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+
+	config := openai.DefaultConfig(apiKey)
+	config.BaseURL = strings.TrimRight(baseURL, "/")
+	config.HTTPClient = &http.Client{Timeout: openAIRequestTimeout}
+
+	return &OpenAIClient{
+		client: openai.NewClientWithConfig(config),
+		model:  model,
+	}, nil
+}
+
+// prompt 发送系统提示词和用户输入，返回模型的回复
+func (c *OpenAIClient) prompt(ctx context.Context, instructions, content string) (string, error) {
 	messages := []openai.ChatCompletionMessage{
 		{
 			Role:    openai.ChatMessageRoleSystem,
-			Content: "你是一个有用的助手，负责总结文章内容。请用中文简明扼要地总结以下内容，不需要任何额外的解释或说明。\\n\\n 如下是需要总结的内容 \\",
+			Content: instructions,
 		},
 		{
 			Role:    openai.ChatMessageRoleUser,
@@ -51,61 +58,140 @@ func PromptSummarize(content string) (string, error) {
 		},
 	}
 
-	// 创建 ChatCompletion 请求
-	resp, err := client.CreateChatCompletion(
-		context.Background(),
-		openai.ChatCompletionRequest{
-			Model:    model,
-			Messages: messages,
-		},
-	)
+	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:    c.model,
+		Messages: messages,
+	})
+
 	if err != nil {
 		return "", err
 	}
 
 	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("no response from OpenAI")
+		return "", fmt.Errorf("no response from %s", c.model)
 	}
 
-	// 返回纯中文总结
-	return resp.Choices[0].Message.Content, nil
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
 }
 
-// PromptTranslate 接受内容字符串并返回中文翻译
-func PromptTranslate(content string) (string, error) {
-	// 确保客户端已初始化
-	if err := initializeClient(); err != nil {
-		return "", err
-	}
+// Summarize 返回使用指定语言简明扼要的总结
+func (c *OpenAIClient) Summarize(ctx context.Context, content, language string) (string, error) {
+	instructions := fmt.Sprintf(
+		"You are a helpful assistant that summarizes content. Summarize the content below concisely in %s, without any additional explanations.",
+		languageName(language),
+	)
 
-	// 定义对话消息，包括系统角色和用户输入
-	messages := []openai.ChatCompletionMessage{
-		{
-			Role:    openai.ChatMessageRoleSystem,
-			Content: "你是一名专业的翻译员，负责将英文文本翻译成中文。请仅提供翻译后的中文内容，不需要任何额外的解释或说明。\n\n 如下是翻译内容 \" ",
-		},
-		{
-			Role:    openai.ChatMessageRoleUser,
-			Content: content,
-		},
+	return c.prompt(ctx, instructions, content)
+}
+
+// 每次请求最多翻译的文本数量，太多时模型容易漏掉或合并其中的条目
+const openAITranslateBatchSize = 20
+
+func translationSource(from string) string {
+	if from != "" && from != AutoDetectLanguage {
+		return "the " + languageName(from) + " text"
 	}
 
-	// 创建 ChatCompletion 请求
-	resp, err := client.CreateChatCompletion(
-		context.Background(),
-		openai.ChatCompletionRequest{
-			Model:    model,
-			Messages: messages,
-		},
+	return "the text"
+}
+
+// translateOne 翻译单段文本，用于批量翻译的结果无法拆分的情况
+func (c *OpenAIClient) translateOne(ctx context.Context, text, from, to string) (string, error) {
+	instructions := fmt.Sprintf(
+		"You are a professional translator. Translate %s below into %s. Reply with only the translation, without any additional explanations.",
+		translationSource(from),
+		languageName(to),
 	)
+
+	return c.prompt(ctx, instructions, text)
+}
+
+// translateBatch 将多段文本作为 JSON 数组一次发送，并要求模型以同样长度的数组回复
+func (c *OpenAIClient) translateBatch(ctx context.Context, texts []string, from, to string) ([]string, error) {
+	instructions := fmt.Sprintf(
+		"You are a professional translator. Translate each element of the JSON array of strings below into %s. "+
+			"Reply with only a JSON array of strings that contains the translations in the same order and has exactly %d elements, without any additional explanations.",
+		languageName(to),
+		len(texts),
+	)
+
+	if from != "" && from != AutoDetectLanguage {
+		instructions += " The strings are in " + languageName(from) + "."
+	}
+
+	content, err := json.Marshal(texts)
+
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("no response from OpenAI")
+	reply, err := c.prompt(ctx, instructions, string(content))
+
+	if err != nil {
+		return nil, err
+	}
+
+	// 有些模型会把回复放在代码块中或在前后附加说明
+	start, end := strings.Index(reply, "["), strings.LastIndex(reply, "]")
+
+	if start == -1 || end < start {
+		return nil, errors.New("reply does not contain a JSON array")
+	}
+
+	var translated []string
+
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &translated); err != nil {
+		return nil, fmt.Errorf("parsing reply: %v", err)
+	}
+
+	if len(translated) != len(texts) {
+		return nil, fmt.Errorf("reply has %d translations instead of %d", len(translated), len(texts))
+	}
+
+	return translated, nil
+}
+
+// Translate 分批翻译文本，无法拆分的批次退回到逐条翻译
+func (c *OpenAIClient) Translate(ctx context.Context, texts []string, from, to string) ([]string, error) {
+	results := make([]string, len(texts))
+	failed := 0
+	var lastErr error
+
+	for start := 0; start < len(texts); start += openAITranslateBatchSize {
+		end := min(start+openAITranslateBatchSize, len(texts))
+		batch := texts[start:end]
+
+		if len(batch) > 1 {
+			translated, err := c.translateBatch(ctx, batch, from, to)
+
+			if err == nil {
+				copy(results[start:end], translated)
+				continue
+			}
+
+			if ctx.Err() != nil {
+				return results, ctx.Err()
+			}
+
+			slog.Warn("Batch translation failed, translating one at a time", "model", c.model, "error", err)
+		}
+
+		for i := range batch {
+			translated, err := c.translateOne(ctx, batch[i], from, to)
+
+			if err != nil {
+				failed++
+				lastErr = err
+				continue
+			}
+
+			results[start+i] = translated
+		}
+	}
+
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d translations failed, last error: %v", failed, len(texts), lastErr)
 	}
 
-	// 返回纯中文翻译
-	return resp.Choices[0].Message.Content, nil
+	return results, nil
 }
@@ -0,0 +1,53 @@
+package widget
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/glanceapp/glance/internal/assets"
+)
+
+type Accordion struct {
+	widgetBase          `yaml:",inline"`
+	containerWidgetBase `yaml:",inline"`
+	Exclusive           bool `yaml:"exclusive"`
+	StartCollapsed      bool `yaml:"start-collapsed"`
+}
+
+func (widget *Accordion) Initialize() error {
+	widget.withTitle("Accordion").withError(nil)
+	widget.HideHeader = true
+
+	// unless collapsed, the first item starts out expanded
+	widget.isVisible = func(index int) bool {
+		return index == 0 && !widget.StartCollapsed
+	}
+
+	return widget.initializeWidgets(true)
+}
+
+func (widget *Accordion) Update(ctx context.Context) {
+	widget.updateWidgets(ctx)
+}
+
+func (widget *Accordion) SetProviders(providers *Providers) {
+	widget.setWidgetsProviders(providers)
+}
+
+func (widget *Accordion) RequiresUpdate(now *time.Time) bool {
+	return widget.widgetsRequireUpdate(now)
+}
+
+func (widget *Accordion) HandleRequest(w http.ResponseWriter, r *http.Request) {
+	widget.handleLazyWidgetRequest(w, r)
+}
+
+func (widget *Accordion) IsOpen(index int) bool {
+	return widget.isVisible(index)
+}
+
+func (widget *Accordion) Render() template.HTML {
+	return widget.render(widget, assets.AccordionTemplate)
+}
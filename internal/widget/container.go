@@ -0,0 +1,138 @@
+package widget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Implemented by widgets that hold other widgets, such as groups, so that
+// the children can be registered and reached through the widget API
+type Container interface {
+	GetWidgets() Widgets
+}
+
+// Shared behavior of the group, accordion and split widgets. With lazy loading
+// only the widgets that are visible when the page loads get updated along with
+// the page, the rest get updated and rendered once they're opened
+type containerWidgetBase struct {
+	Widgets   Widgets `yaml:"widgets"`
+	LazyLoad  bool    `yaml:"lazy-load"`
+	isVisible func(int) bool
+	mu        sync.Mutex
+}
+
+func (container *containerWidgetBase) GetWidgets() Widgets {
+	return container.Widgets
+}
+
+func (container *containerWidgetBase) initializeWidgets(hideHeaders bool) error {
+	if len(container.Widgets) == 0 {
+		return errors.New("at least one widget is required")
+	}
+
+	for i := range container.Widgets {
+		if _, ok := container.Widgets[i].(PageWidgetsConsumer); ok {
+			return fmt.Errorf("%s widgets cannot be placed inside containers", container.Widgets[i].GetType())
+		}
+
+		if nested, ok := container.Widgets[i].(Container); ok {
+			for _, child := range nested.GetWidgets() {
+				if _, ok := child.(Container); ok {
+					return errors.New("containers can only be nested one level deep")
+				}
+			}
+		}
+
+		if hideHeaders {
+			container.Widgets[i].SetHideHeader(true)
+		}
+
+		if err := container.Widgets[i].Initialize(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (container *containerWidgetBase) IsLazy(index int) bool {
+	return container.LazyLoad && container.isVisible != nil && !container.isVisible(index)
+}
+
+func (container *containerWidgetBase) updateWidgets(ctx context.Context) {
+	container.mu.Lock()
+	defer container.mu.Unlock()
+
+	var wg sync.WaitGroup
+	now := time.Now()
+
+	for w := range container.Widgets {
+		widget := container.Widgets[w]
+
+		if container.IsLazy(w) || !widget.RequiresUpdate(&now) {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			widget.Update(ctx)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (container *containerWidgetBase) widgetsRequireUpdate(now *time.Time) bool {
+	for i := range container.Widgets {
+		if !container.IsLazy(i) && container.Widgets[i].RequiresUpdate(now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (container *containerWidgetBase) setWidgetsProviders(providers *Providers) {
+	for i := range container.Widgets {
+		container.Widgets[i].SetProviders(providers)
+	}
+}
+
+// Serves /api/widgets/{id}/tabs/{index}, updating the widget if needed and
+// responding with its rendered HTML. Expected to be called with the lock of
+// the container's page held, as the page's other widgets read its state
+func (container *containerWidgetBase) handleLazyWidgetRequest(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.PathValue("path"), "/")
+
+	if !strings.HasPrefix(path, "tabs/") {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	index, err := strconv.Atoi(strings.TrimPrefix(path, "tabs/"))
+
+	if err != nil || index < 0 || index >= len(container.Widgets) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	container.mu.Lock()
+	defer container.mu.Unlock()
+
+	widget := container.Widgets[index]
+	now := time.Now()
+
+	if widget.RequiresUpdate(&now) {
+		widget.Update(context.Background())
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(widget.Render()))
+}
@@ -0,0 +1,129 @@
+package widget
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/glanceapp/glance/internal/assets"
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+type CustomAPI struct {
+	widgetBase       `yaml:",inline"`
+	URL              OptionalEnvString            `yaml:"url"`
+	Method           string                       `yaml:"method"`
+	Headers          map[string]OptionalEnvString `yaml:"headers"`
+	Body             string                       `yaml:"body"`
+	ResponseType     string                       `yaml:"response-type"`
+	AllowInsecure    bool                         `yaml:"allow-insecure"`
+	Template         string                       `yaml:"template"`
+	TemplateFile     string                       `yaml:"template-file"`
+	CompiledHTML     template.HTML                `yaml:"-"`
+	compiledTemplate *template.Template           `yaml:"-"`
+}
+
+func (widget *CustomAPI) Initialize() error {
+	widget.withTitle("Custom API").withCacheDuration(1 * time.Hour)
+
+	if widget.URL == "" {
+		return errors.New("URL is required for the custom API widget")
+	}
+
+	if widget.Template == "" && widget.TemplateFile == "" {
+		return errors.New("either template or template-file is required for the custom API widget")
+	}
+
+	if widget.Template != "" && widget.TemplateFile != "" {
+		return errors.New("template and template-file cannot both be specified")
+	}
+
+	widget.Method = strings.ToUpper(widget.Method)
+
+	if widget.Method == "" {
+		widget.Method = http.MethodGet
+	}
+
+	switch widget.ResponseType {
+	case "", "auto", "json", "xml", "text":
+	default:
+		return fmt.Errorf("invalid response-type %s, must be one of auto, json, xml or text", widget.ResponseType)
+	}
+
+	if widget.Template != "" {
+		compiled, err := assets.CompileUserTemplate("custom-api", widget.Template)
+
+		if err != nil {
+			return fmt.Errorf("failed parsing custom API template: %v", err)
+		}
+
+		widget.compiledTemplate = compiled
+	}
+
+	return nil
+}
+
+// Templates from files can only be loaded once the assets path is known
+func (widget *CustomAPI) loadTemplateFile() error {
+	contents, err := widget.readAssetFile(widget.TemplateFile)
+
+	if err != nil {
+		return fmt.Errorf("template-file: %v", err)
+	}
+
+	compiled, err := assets.CompileUserTemplate(widget.TemplateFile, string(contents))
+
+	if err != nil {
+		return fmt.Errorf("failed parsing template-file: %v", err)
+	}
+
+	widget.compiledTemplate = compiled
+
+	return nil
+}
+
+func (widget *CustomAPI) Update(ctx context.Context) {
+	if widget.compiledTemplate == nil {
+		if err := widget.loadTemplateFile(); err != nil {
+			widget.withError(err).scheduleNextUpdate()
+			return
+		}
+	}
+
+	headers := make(map[string]string, len(widget.Headers))
+
+	for key, value := range widget.Headers {
+		headers[key] = value.String()
+	}
+
+	response, err := feed.FetchCustomAPI(feed.CustomAPIRequest{
+		URL:           widget.URL.String(),
+		Method:        widget.Method,
+		Headers:       headers,
+		Body:          widget.Body,
+		ResponseType:  widget.ResponseType,
+		AllowInsecure: widget.AllowInsecure,
+	})
+
+	if !widget.canContinueUpdateAfterHandlingErr(err) {
+		return
+	}
+
+	var buffer bytes.Buffer
+
+	if err := widget.compiledTemplate.Execute(&buffer, response); err != nil {
+		widget.withError(fmt.Errorf("failed executing template: %v", err))
+		return
+	}
+
+	widget.CompiledHTML = template.HTML(buffer.String())
+}
+
+func (widget *CustomAPI) Render() template.HTML {
+	return widget.render(widget, assets.CustomAPITemplate)
+}
@@ -0,0 +1,244 @@
+package widget
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/glanceapp/glance/internal/assets"
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+var errNoSummarizer = errors.New("digest requires a model to be configured in the ai section")
+
+// Implemented by widgets that build on the content of the other widgets on
+// their page, these get updated after the rest of the page's widgets
+type PageWidgetsConsumer interface {
+	SetPageWidgets(Widgets)
+}
+
+// Implemented by widgets whose content can be included in a digest
+type digestSource interface {
+	digestItems() []feed.DigestItem
+}
+
+func (widget *RSS) digestItems() []feed.DigestItem {
+	widget.mu.Lock()
+	defer widget.mu.Unlock()
+
+	return widget.Items.DigestItems()
+}
+
+func (widget *HackerNews) digestItems() []feed.DigestItem {
+	return widget.Posts.DigestItems()
+}
+
+func (widget *Lobsters) digestItems() []feed.DigestItem {
+	return widget.Posts.DigestItems()
+}
+
+func (widget *Reddit) digestItems() []feed.DigestItem {
+	return widget.Posts.DigestItems()
+}
+
+func (widget *Releases) digestItems() []feed.DigestItem {
+	return widget.Releases.DigestItems()
+}
+
+type Digest struct {
+	widgetBase     `yaml:",inline"`
+	Sources        []string         `yaml:"sources"`
+	Language       LanguageField    `yaml:"language"`
+	Period         DurationField    `yaml:"period"`
+	ItemsPerSource int              `yaml:"items-per-source"`
+	RegenerateAt   []TimeOfDayField `yaml:"regenerate-at"`
+	CollapseAfter  int              `yaml:"collapse-after"`
+	Digest         *feed.Digest     `yaml:"-"`
+	pageWidgets    Widgets
+	loaded         bool
+}
+
+func (widget *Digest) Initialize() error {
+	widget.withTitle("Digest")
+
+	if widget.Language == "" {
+		widget.Language = "en"
+	}
+
+	if widget.Period <= 0 {
+		widget.Period = DurationField(24 * time.Hour)
+	}
+
+	if widget.ItemsPerSource <= 0 {
+		widget.ItemsPerSource = 10
+	}
+
+	if widget.CollapseAfter == 0 || widget.CollapseAfter < -1 {
+		widget.CollapseAfter = 3
+	}
+
+	if len(widget.RegenerateAt) == 0 {
+		widget.RegenerateAt = []TimeOfDayField{TimeOfDayField(7 * time.Hour)}
+	}
+
+	schedule := make([]time.Duration, len(widget.RegenerateAt))
+
+	for i := range widget.RegenerateAt {
+		schedule[i] = time.Duration(widget.RegenerateAt[i])
+	}
+
+	widget.withCacheSchedule(schedule)
+
+	return nil
+}
+
+func (widget *Digest) SetPageWidgets(widgets Widgets) {
+	widget.pageWidgets = widgets
+}
+
+func widgetTitle(w Widget) string {
+	if titled, ok := w.(interface{ getTitle() string }); ok && titled.getTitle() != "" {
+		return titled.getTitle()
+	}
+
+	return w.GetType()
+}
+
+func (widget *Digest) includesSource(source Widget) bool {
+	if len(widget.Sources) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(widget.Sources, func(name string) bool {
+		return strings.EqualFold(name, source.GetType()) || strings.EqualFold(name, widgetTitle(source))
+	})
+}
+
+func (widget *Digest) collectSources() []feed.DigestSource {
+	since := time.Now().Add(-time.Duration(widget.Period))
+	sources := make([]feed.DigestSource, 0, len(widget.pageWidgets))
+
+	for _, pageWidget := range widget.pageWidgets {
+		source, ok := pageWidget.(digestSource)
+
+		if !ok || !widget.includesSource(pageWidget) {
+			continue
+		}
+
+		items := make([]feed.DigestItem, 0, widget.ItemsPerSource)
+
+		// the widgets' own order is kept since some of them rank their items
+		for _, item := range source.digestItems() {
+			if item.PublishedAt.Before(since) {
+				continue
+			}
+
+			items = append(items, item)
+
+			if len(items) == widget.ItemsPerSource {
+				break
+			}
+		}
+
+		if len(items) == 0 {
+			continue
+		}
+
+		sources = append(sources, feed.DigestSource{Name: widgetTitle(pageWidget), Items: items})
+	}
+
+	return sources
+}
+
+// Digests are persisted to the data path when one is set so that restarting
+// doesn't summarize everything again before the next scheduled time
+func (widget *Digest) digestPath() string {
+	if widget.Providers == nil || widget.Providers.DataPath == "" {
+		return ""
+	}
+
+	sources := slices.Clone(widget.Sources)
+	slices.Sort(sources)
+	hash := sha256.Sum256([]byte(widget.Title + "\n" + string(widget.Language) + "\n" + strings.Join(sources, "\n")))
+
+	return filepath.Join(widget.Providers.DataPath, "digest", hex.EncodeToString(hash[:8])+".json")
+}
+
+func (widget *Digest) loadDigest() {
+	widget.loaded = true
+	path := widget.digestPath()
+
+	if path == "" {
+		return
+	}
+
+	var digest feed.Digest
+
+	if err := feed.ReadJSONFile(path, &digest); err != nil {
+		slog.Error("failed to load digest", "path", path, "error", err)
+		return
+	}
+
+	if !digest.GeneratedAt.IsZero() {
+		widget.Digest = &digest
+	}
+}
+
+func (widget *Digest) saveDigest() {
+	path := widget.digestPath()
+
+	if path == "" || widget.Digest == nil {
+		return
+	}
+
+	if err := feed.WriteJSONFile(path, widget.Digest); err != nil {
+		slog.Error("failed to save digest", "path", path, "error", err)
+	}
+}
+
+func (widget *Digest) Update(ctx context.Context) {
+	if !widget.loaded {
+		widget.loadDigest()
+
+		// still up to date if no scheduled time has passed since it was generated
+		if widget.Digest != nil && time.Now().Before(nextScheduledTime(widget.Digest.GeneratedAt, widget.schedule)) {
+			widget.canContinueUpdateAfterHandlingErr(nil)
+			return
+		}
+	}
+
+	if widget.Providers == nil || widget.Providers.Summarizer == nil {
+		widget.canContinueUpdateAfterHandlingErr(errNoSummarizer)
+		return
+	}
+
+	sources := widget.collectSources()
+
+	if len(sources) == 0 {
+		widget.canContinueUpdateAfterHandlingErr(
+			fmt.Errorf("%w: none of the widgets on the page have recent items", feed.ErrNoContent),
+		)
+		return
+	}
+
+	digest, err := feed.GenerateDigest(ctx, widget.Providers.Summarizer, sources, string(widget.Language))
+
+	if !widget.canContinueUpdateAfterHandlingErr(err) {
+		return
+	}
+
+	widget.Digest = digest
+	widget.saveDigest()
+}
+
+func (widget *Digest) Render() template.HTML {
+	return widget.render(widget, assets.DigestTemplate)
+}
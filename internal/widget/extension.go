@@ -16,6 +16,7 @@ type Extension struct {
 	URL        string            `yaml:"url"`
 	Parameters map[string]string `yaml:"parameters"`
 	AllowHtml  bool              `yaml:"allow-potentially-dangerous-html"`
+	BasicHtml  bool              `yaml:"allow-basic-html"`
 	Extension  feed.Extension    `yaml:"-"`
 	cachedHTML template.HTML     `yaml:"-"`
 }
@@ -41,6 +42,7 @@ func (widget *Extension) Update(ctx context.Context) {
 		URL:        widget.URL,
 		Parameters: widget.Parameters,
 		AllowHtml:  widget.AllowHtml,
+		BasicHtml:  widget.BasicHtml,
 	})
 
 	widget.canContinueUpdateAfterHandlingErr(err)
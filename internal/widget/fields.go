@@ -9,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/glanceapp/glance/internal/parser"
+	"github.com/glanceapp/glance/internal/tool"
 	"gopkg.in/yaml.v3"
 )
 
@@ -122,6 +124,59 @@ func (d *DurationField) UnmarshalYAML(node *yaml.Node) error {
 	return nil
 }
 
+// Parses a time of the day in the format HH:MM into the time since midnight
+func ParseTimeOfDay(value string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", value)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %s, must be in the format HH:MM", value)
+	}
+
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// A time of the day in the format HH:MM
+type TimeOfDayField time.Duration
+
+func (f *TimeOfDayField) UnmarshalYAML(node *yaml.Node) error {
+	var value string
+
+	if err := node.Decode(&value); err != nil {
+		return err
+	}
+
+	parsed, err := ParseTimeOfDay(value)
+
+	if err != nil {
+		return err
+	}
+
+	*f = TimeOfDayField(parsed)
+
+	return nil
+}
+
+// A BCP 47 language code such as zh, ja or pt-BR
+type LanguageField string
+
+func (f *LanguageField) UnmarshalYAML(node *yaml.Node) error {
+	var value string
+
+	if err := node.Decode(&value); err != nil {
+		return err
+	}
+
+	language, err := tool.ParseLanguage(value)
+
+	if err != nil {
+		return err
+	}
+
+	*f = LanguageField(language)
+
+	return nil
+}
+
 type OptionalEnvString string
 
 func (f *OptionalEnvString) UnmarshalYAML(node *yaml.Node) error {
@@ -166,3 +221,42 @@ func toSimpleIconIfPrefixed(icon string) (string, bool) {
 
 	return icon, true
 }
+
+// Selects how pages are fetched by widgets that extract content from them
+type RendererField struct {
+	Type          string        `yaml:"type"`
+	URL           string        `yaml:"url"`
+	Command       string        `yaml:"command"`
+	Args          []string      `yaml:"args"`
+	Timeout       DurationField `yaml:"timeout"`
+	Wait          DurationField `yaml:"wait"`
+	MaxConcurrent int           `yaml:"max-concurrent"`
+}
+
+// Pages are fetched without running their scripts when no renderer is configured
+func newRenderer(field *RendererField) (parser.Renderer, error) {
+	if field == nil {
+		return parser.NewHTTPRenderer(parser.RenderOptions{}), nil
+	}
+
+	options := parser.RenderOptions{
+		Timeout:       time.Duration(field.Timeout),
+		Wait:          time.Duration(field.Wait),
+		MaxConcurrent: field.MaxConcurrent,
+	}
+
+	switch field.Type {
+	case "", "http":
+		return parser.NewHTTPRenderer(options), nil
+	case "browser":
+		if field.URL == "" {
+			return nil, fmt.Errorf("url is required for the browser renderer")
+		}
+
+		return parser.NewBrowserRenderer(field.URL, options)
+	case "command":
+		return parser.NewCommandRenderer(field.Command, field.Args, options)
+	}
+
+	return nil, fmt.Errorf("unknown renderer type %s, must be one of http, browser or command", field.Type)
+}
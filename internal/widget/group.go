@@ -2,73 +2,44 @@ package widget
 
 import (
 	"context"
-	"errors"
 	"html/template"
-	"sync"
+	"net/http"
 	"time"
 
 	"github.com/glanceapp/glance/internal/assets"
 )
 
 type Group struct {
-	widgetBase `yaml:",inline"`
-	Widgets    Widgets `yaml:"widgets"`
+	widgetBase          `yaml:",inline"`
+	containerWidgetBase `yaml:",inline"`
 }
 
 func (widget *Group) Initialize() error {
-	widget.withError(nil)
+	widget.withTitle("Group").withError(nil)
 	widget.HideHeader = true
 
-	for i := range widget.Widgets {
-		widget.Widgets[i].SetHideHeader(true)
-
-		if widget.Widgets[i].GetType() == "group" {
-			return errors.New("nested groups are not allowed")
-		}
-
-		if err := widget.Widgets[i].Initialize(); err != nil {
-			return err
-		}
+	// only the first tab is visible when the page loads
+	widget.isVisible = func(index int) bool {
+		return index == 0
 	}
 
-	return nil
+	return widget.initializeWidgets(true)
 }
 
 func (widget *Group) Update(ctx context.Context) {
-	var wg sync.WaitGroup
-	now := time.Now()
-
-	for w := range widget.Widgets {
-		widget := widget.Widgets[w]
-
-		if !widget.RequiresUpdate(&now) {
-			continue
-		}
-
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			widget.Update(ctx)
-		}()
-	}
-
-	wg.Wait()
+	widget.updateWidgets(ctx)
 }
 
 func (widget *Group) SetProviders(providers *Providers) {
-	for i := range widget.Widgets {
-		widget.Widgets[i].SetProviders(providers)
-	}
+	widget.setWidgetsProviders(providers)
 }
 
 func (widget *Group) RequiresUpdate(now *time.Time) bool {
-	for i := range widget.Widgets {
-		if widget.Widgets[i].RequiresUpdate(now) {
-			return true
-		}
-	}
+	return widget.widgetsRequireUpdate(now)
+}
 
-	return false
+func (widget *Group) HandleRequest(w http.ResponseWriter, r *http.Request) {
+	widget.handleLazyWidgetRequest(w, r)
 }
 
 func (widget *Group) Render() template.HTML {
@@ -1,8 +1,12 @@
 package widget
 
 import (
+	"bytes"
 	"context"
 	"html/template"
+	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/glanceapp/glance/internal/assets"
@@ -17,7 +21,12 @@ type HackerNews struct {
 	ExtraSortBy         string          `yaml:"extra-sort-by"`
 	CollapseAfter       int             `yaml:"collapse-after"`
 	CommentsUrlTemplate string          `yaml:"comments-url-template"`
+	CommentsView        bool            `yaml:"comments-view"`
+	CommentsDepth       int             `yaml:"comments-depth"`
+	CommentsLimit       int             `yaml:"comments-limit"`
+	TranslateTitles     LanguageField   `yaml:"translate-titles"`
 	ShowThumbnails      bool            `yaml:"-"`
+	mu                  sync.Mutex      `yaml:"-"`
 }
 
 func (widget *HackerNews) Initialize() error {
@@ -38,6 +47,14 @@ func (widget *HackerNews) Initialize() error {
 		widget.SortBy = "top"
 	}
 
+	if widget.CommentsDepth <= 0 {
+		widget.CommentsDepth = 4
+	}
+
+	if widget.CommentsLimit <= 0 {
+		widget.CommentsLimit = 150
+	}
+
 	return nil
 }
 
@@ -57,7 +74,78 @@ func (widget *HackerNews) Update(ctx context.Context) {
 		posts = posts[:widget.Limit]
 	}
 
+	if widget.TranslateTitles != "" {
+		widget.translateForumPostTitles(ctx, widget.TranslateTitles, posts)
+	}
+
+	widget.mu.Lock()
 	widget.Posts = posts
+	widget.mu.Unlock()
+}
+
+func (widget *HackerNews) SyndicationItems() []feed.SyndicationItem {
+	return widget.Posts.ToSyndicationItems()
+}
+
+func (widget *HackerNews) HandleRequest(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("path") != "comments" || !widget.CommentsView {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	widget.handleCommentsRequest(w, r)
+}
+
+// Only the comments of posts that are currently displayed can be opened,
+// otherwise the endpoint could be used to make arbitrary requests to the API
+func (widget *HackerNews) findPostByID(id int) (feed.ForumPost, bool) {
+	widget.mu.Lock()
+	defer widget.mu.Unlock()
+
+	for i := range widget.Posts {
+		if widget.Posts[i].ID == id {
+			return widget.Posts[i], true
+		}
+	}
+
+	return feed.ForumPost{}, false
+}
+
+func (widget *HackerNews) handleCommentsRequest(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	post, found := widget.findPostByID(id)
+
+	if !found {
+		http.Error(w, "post not found", http.StatusNotFound)
+		return
+	}
+
+	thread, err := feed.FetchHackerNewsThread(r.Context(), post.ID, feed.HackerNewsThreadOptions{
+		MaxDepth:    widget.CommentsDepth,
+		MaxComments: widget.CommentsLimit,
+	})
+
+	data := struct {
+		Post   *feed.ForumPost
+		Thread *feed.HackerNewsThread
+		Error  error
+	}{Post: &post, Thread: thread, Error: err}
+
+	var buffer bytes.Buffer
+
+	if err := assets.HackerNewsCommentsTemplate.Execute(&buffer, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buffer.Bytes())
 }
 
 func (widget *HackerNews) Render() template.HTML {
@@ -10,15 +10,17 @@ import (
 )
 
 type Lobsters struct {
-	widgetBase     `yaml:",inline"`
-	Posts          feed.ForumPosts `yaml:"-"`
-	InstanceURL    string          `yaml:"instance-url"`
-	CustomURL      string          `yaml:"custom-url"`
-	Limit          int             `yaml:"limit"`
-	CollapseAfter  int             `yaml:"collapse-after"`
-	SortBy         string          `yaml:"sort-by"`
-	Tags           []string        `yaml:"tags"`
-	ShowThumbnails bool            `yaml:"-"`
+	widgetBase      `yaml:",inline"`
+	Posts           feed.ForumPosts `yaml:"-"`
+	InstanceURL     string          `yaml:"instance-url"`
+	CustomURL       string          `yaml:"custom-url"`
+	Limit           int             `yaml:"limit"`
+	CollapseAfter   int             `yaml:"collapse-after"`
+	SortBy          string          `yaml:"sort-by"`
+	Tags            []string        `yaml:"tags"`
+	TranslateTitles LanguageField   `yaml:"translate-titles"`
+	ShowThumbnails  bool            `yaml:"-"`
+	CommentsView    bool            `yaml:"-"`
 }
 
 func (widget *Lobsters) Initialize() error {
@@ -56,9 +58,17 @@ func (widget *Lobsters) Update(ctx context.Context) {
 		posts = posts[:widget.Limit]
 	}
 
+	if widget.TranslateTitles != "" {
+		widget.translateForumPostTitles(ctx, widget.TranslateTitles, posts)
+	}
+
 	widget.Posts = posts
 }
 
+func (widget *Lobsters) SyndicationItems() []feed.SyndicationItem {
+	return widget.Posts.ToSyndicationItems()
+}
+
 func (widget *Lobsters) Render() template.HTML {
 	return widget.render(widget, assets.ForumPostsTemplate)
 }
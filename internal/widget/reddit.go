@@ -26,6 +26,8 @@ type Reddit struct {
 	Limit               int             `yaml:"limit"`
 	CollapseAfter       int             `yaml:"collapse-after"`
 	RequestUrlTemplate  string          `yaml:"request-url-template"`
+	TranslateTitles     LanguageField   `yaml:"translate-titles"`
+	CommentsView        bool            `yaml:"-"`
 }
 
 func (widget *Reddit) Initialize() error {
@@ -104,9 +106,17 @@ func (widget *Reddit) Update(ctx context.Context) {
 		posts.SortByEngagement()
 	}
 
+	if widget.TranslateTitles != "" {
+		widget.translateForumPostTitles(ctx, widget.TranslateTitles, posts)
+	}
+
 	widget.Posts = posts
 }
 
+func (widget *Reddit) SyndicationItems() []feed.SyndicationItem {
+	return widget.Posts.ToSyndicationItems()
+}
+
 func (widget *Reddit) Render() template.HTML {
 	if widget.Style == "horizontal-cards" {
 		return widget.render(widget, assets.RedditCardsHorizontalTemplate)
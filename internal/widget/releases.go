@@ -98,6 +98,10 @@ func (widget *Releases) Update(ctx context.Context) {
 	widget.Releases = releases
 }
 
+func (widget *Releases) SyndicationItems() []feed.SyndicationItem {
+	return widget.Releases.ToSyndicationItems()
+}
+
 func (widget *Releases) Render() template.HTML {
 	return widget.render(widget, assets.ReleasesTemplate)
 }
@@ -0,0 +1,287 @@
+package widget
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+// What the RSS widget remembers between updates, persisted to the data path
+// when one is configured so that it survives restarts
+type rssHistory struct {
+	// item keys mapped to the unix time they were marked as read
+	Read map[string]int64 `json:"read"`
+	// the items the feeds returned during the last update
+	Current feed.RSSFeedItems `json:"current"`
+	// items that are no longer returned by the feeds
+	Archive feed.RSSFeedItems `json:"archive"`
+	// item keys mapped to the position in seconds their episode was paused at
+	Playback map[string]int `json:"playback"`
+}
+
+func (widget *RSS) usesHistory() bool {
+	return widget.TrackRead || widget.ArchiveLimit > 0 || widget.Style == "podcast"
+}
+
+// Widgets that persist to the same file share a single history in memory so
+// that they don't overwrite each other's changes when saving it
+type rssHistoryStore struct {
+	path    string
+	history rssHistory
+	// the largest archive limit of the widgets using the history
+	archiveLimit int
+	mu           sync.Mutex
+}
+
+var rssHistoryStores = struct {
+	stores map[string]*rssHistoryStore
+	mu     sync.Mutex
+}{stores: make(map[string]*rssHistoryStore)}
+
+// The history is kept per widget title and set of feeds, so widgets only share
+// it when they display the same thing, such as the same widget on two pages
+func (widget *RSS) historyPath() string {
+	if widget.Providers == nil || widget.Providers.DataPath == "" {
+		return ""
+	}
+
+	urls := make([]string, len(widget.FeedRequests))
+
+	for i := range widget.FeedRequests {
+		urls[i] = widget.FeedRequests[i].Url
+	}
+
+	sort.Strings(urls)
+	hash := sha256.Sum256([]byte(widget.Title + "\n" + strings.Join(urls, "\n")))
+
+	return filepath.Join(widget.Providers.DataPath, "rss", hex.EncodeToString(hash[:8])+".json")
+}
+
+func (widget *RSS) loadHistory() {
+	path := widget.historyPath()
+
+	// without a data path nothing gets persisted, so there's nothing to share
+	if path == "" {
+		widget.history = &rssHistoryStore{archiveLimit: widget.ArchiveLimit}
+		return
+	}
+
+	rssHistoryStores.mu.Lock()
+	defer rssHistoryStores.mu.Unlock()
+
+	store, exists := rssHistoryStores.stores[path]
+
+	if !exists {
+		store = &rssHistoryStore{path: path}
+
+		if err := feed.ReadJSONFile(path, &store.history); err != nil {
+			slog.Error("failed to load rss history", "path", path, "error", err)
+		}
+
+		rssHistoryStores.stores[path] = store
+	}
+
+	store.mu.Lock()
+	store.archiveLimit = max(store.archiveLimit, widget.ArchiveLimit)
+	store.mu.Unlock()
+
+	widget.history = store
+}
+
+// Must be called with the store's lock held
+func (store *rssHistoryStore) save() {
+	if store.path == "" {
+		return
+	}
+
+	if err := feed.WriteJSONFile(store.path, &store.history); err != nil {
+		slog.Error("failed to save rss history", "path", store.path, "error", err)
+	}
+}
+
+// Moves items that dropped out of the feeds into the archive, returns the
+// archived items this widget should display after the current ones
+func (widget *RSS) applyHistory(items feed.RSSFeedItems) feed.RSSFeedItems {
+	if widget.history == nil {
+		widget.loadHistory()
+	}
+
+	widget.history.mu.Lock()
+	defer widget.history.mu.Unlock()
+
+	history := &widget.history.history
+	seen := make(map[string]struct{}, len(items))
+
+	for i := range items {
+		seen[items[i].Key()] = struct{}{}
+	}
+
+	archive := make(feed.RSSFeedItems, 0, widget.history.archiveLimit)
+
+	// the archive is shared with widgets that display the same feeds, so it's
+	// kept up to date as long as any of them uses it
+	if widget.history.archiveLimit > 0 {
+		previous := make(feed.RSSFeedItems, 0, len(history.Current)+len(history.Archive))
+		previous = append(previous, history.Current...)
+		previous = append(previous, history.Archive...)
+
+		for i := range previous {
+			key := previous[i].Key()
+
+			if _, exists := seen[key]; exists {
+				continue
+			}
+
+			seen[key] = struct{}{}
+			archive = append(archive, previous[i])
+		}
+
+		archive.SortByNewest()
+
+		if len(archive) > widget.history.archiveLimit {
+			archive = archive[:widget.history.archiveLimit]
+		}
+	}
+
+	if widget.TrackRead {
+		// without any history there's no way to know what's new, so start
+		// from the current items rather than flagging everything as unread
+		if history.Read == nil {
+			history.Read = make(map[string]int64, len(items))
+			now := time.Now().Unix()
+
+			for i := range items {
+				history.Read[items[i].Key()] = now
+			}
+		}
+
+		// forget items that can no longer be displayed, with some leeway for
+		// feeds that temporarily failed or briefly dropped an item
+		forgetBefore := time.Now().Add(-30 * 24 * time.Hour).Unix()
+
+		for key, readAt := range history.Read {
+			if _, exists := seen[key]; !exists && readAt < forgetBefore {
+				delete(history.Read, key)
+			}
+		}
+	}
+
+	// the widgets sharing the history flag their own copies of the items
+	history.Current = slices.Clone(items)
+	history.Archive = archive
+	widget.history.save()
+
+	if len(archive) > widget.ArchiveLimit {
+		archive = archive[:widget.ArchiveLimit]
+	}
+
+	for key := range history.Playback {
+		if _, exists := seen[key]; !exists {
+			delete(history.Playback, key)
+		}
+	}
+
+	return slices.Clone(archive)
+}
+
+// Flags the items that haven't been read yet and sets where their episodes were
+// paused, done right before displaying them so that changes made while the
+// items were being prepared aren't lost
+func (widget *RSS) applyItemState(items feed.RSSFeedItems) {
+	if widget.history == nil {
+		return
+	}
+
+	widget.history.mu.Lock()
+	defer widget.history.mu.Unlock()
+
+	history := &widget.history.history
+
+	for i := range items {
+		key := items[i].Key()
+
+		if widget.TrackRead {
+			_, read := history.Read[key]
+			items[i].Unread = !read
+		}
+
+		items[i].PlaybackPosition = history.Playback[key]
+	}
+}
+
+// Remembers where the episode was paused, a position of zero forgets it
+func (widget *RSS) setPlaybackPosition(key string, position int) bool {
+	if widget.history == nil {
+		return false
+	}
+
+	widget.history.mu.Lock()
+	defer widget.history.mu.Unlock()
+
+	history := &widget.history.history
+
+	for i := range widget.Items {
+		if widget.Items[i].Key() != key {
+			continue
+		}
+
+		widget.Items[i].PlaybackPosition = position
+
+		if position == 0 {
+			delete(history.Playback, key)
+		} else {
+			if history.Playback == nil {
+				history.Playback = make(map[string]int)
+			}
+
+			history.Playback[key] = position
+		}
+
+		widget.history.save()
+
+		return true
+	}
+
+	return false
+}
+
+// Marks the item with the given key as read, or all displayed items if the key is empty
+func (widget *RSS) markRead(key string) {
+	if widget.history == nil {
+		return
+	}
+
+	widget.history.mu.Lock()
+	defer widget.history.mu.Unlock()
+
+	now := time.Now().Unix()
+
+	for i := range widget.Items {
+		if key != "" && widget.Items[i].Key() != key {
+			continue
+		}
+
+		widget.Items[i].Unread = false
+		widget.history.history.Read[widget.Items[i].Key()] = now
+	}
+
+	widget.history.save()
+}
+
+func (widget *RSS) updateUnreadCount() {
+	widget.UnreadCount = 0
+
+	for i := range widget.Items {
+		if widget.Items[i].Unread {
+			widget.UnreadCount++
+		}
+	}
+}
@@ -1,25 +1,51 @@
 package widget
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"html/template"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/glanceapp/glance/internal/assets"
 	"github.com/glanceapp/glance/internal/feed"
+	"github.com/glanceapp/glance/internal/parser"
 )
 
 type RSS struct {
-	widgetBase       `yaml:",inline"`
-	FeedRequests     []feed.RSSFeedRequest `yaml:"feeds"`
-	Style            string                `yaml:"style"`
-	ThumbnailHeight  float64               `yaml:"thumbnail-height"`
-	CardHeight       float64               `yaml:"card-height"`
-	Items            feed.RSSFeedItems     `yaml:"-"`
-	Limit            int                   `yaml:"limit"`
-	CollapseAfter    int                   `yaml:"collapse-after"`
-	SingleLineTitles bool                  `yaml:"single-line-titles"`
-	NoItemsMessage   string                `yaml:"-"`
+	widgetBase          `yaml:",inline"`
+	FeedRequests        []feed.RSSFeedRequest `yaml:"feeds"`
+	OPML                string                `yaml:"opml"`
+	OPMLCategories      []string              `yaml:"opml-categories"`
+	Filter              *feed.RSSFilter       `yaml:"filter"`
+	Style               string                `yaml:"style"`
+	ThumbnailHeight     float64               `yaml:"thumbnail-height"`
+	CardHeight          float64               `yaml:"card-height"`
+	Items               feed.RSSFeedItems     `yaml:"-"`
+	Limit               int                   `yaml:"limit"`
+	CollapseAfter       int                   `yaml:"collapse-after"`
+	SingleLineTitles    bool                  `yaml:"single-line-titles"`
+	TrackRead           bool                  `yaml:"track-read"`
+	ArchiveLimit        int                   `yaml:"archive-limit"`
+	Deduplicate         bool                  `yaml:"deduplicate"`
+	SimilarityThreshold float64               `yaml:"similarity-threshold"`
+	ReaderView          bool                  `yaml:"reader-view"`
+	TranslateTitles     LanguageField         `yaml:"translate-titles"`
+	Renderer            *RendererField        `yaml:"renderer"`
+	NoItemsMessage      string                `yaml:"-"`
+	UnreadCount         int                   `yaml:"-"`
+	history             *rssHistoryStore      `yaml:"-"`
+	opmlLoaded          bool                  `yaml:"-"`
+	renderer            parser.Renderer       `yaml:"-"`
+	feedRefreshInterval time.Duration         `yaml:"-"`
+	mu                  sync.Mutex            `yaml:"-"`
 }
 
 func (widget *RSS) Initialize() error {
@@ -41,32 +67,353 @@ func (widget *RSS) Initialize() error {
 		widget.CardHeight = 0
 	}
 
-	if widget.Style == "detailed-list" {
-		for i := range widget.FeedRequests {
-			widget.FeedRequests[i].IsDetailed = true
+	if widget.Filter != nil {
+		if err := widget.Filter.Initialize(); err != nil {
+			return fmt.Errorf("filter: %v", err)
+		}
+	}
+
+	if widget.ReaderView {
+		renderer, err := newRenderer(widget.Renderer)
+
+		if err != nil {
+			return fmt.Errorf("renderer: %v", err)
+		}
+
+		widget.renderer = renderer
+	}
+
+	if widget.OPML == "" && len(widget.OPMLCategories) > 0 {
+		return errors.New("opml-categories requires opml to be set")
+	}
+
+	widget.feedRefreshInterval = widget.cacheDuration
+
+	if err := widget.prepareFeedRequests(widget.FeedRequests); err != nil {
+		return err
+	}
+
+	// feeds are only refreshed when the widget updates, so it has to
+	// update at least as often as the most frequently refreshed feed
+	for i := range widget.FeedRequests {
+		if interval := widget.FeedRequests[i].GetRefreshInterval(); interval > 0 && interval < widget.cacheDuration {
+			widget.cacheDuration = interval
 		}
 	}
 
+	if widget.SimilarityThreshold == 0 {
+		widget.SimilarityThreshold = 0.5
+	}
+
+	if widget.SimilarityThreshold < 0 || widget.SimilarityThreshold > 1 {
+		return errors.New("similarity-threshold must be between 0 and 1")
+	}
+
+	if widget.ArchiveLimit < 0 {
+		return errors.New("archive-limit cannot be negative")
+	}
+
 	widget.NoItemsMessage = "No items were returned from the feeds."
 
 	return nil
 }
 
+func (widget *RSS) prepareFeedRequests(requests []feed.RSSFeedRequest) error {
+	for i := range requests {
+		request := &requests[i]
+		request.IsDetailed = widget.Style == "detailed-list"
+		request.WidgetFilter = widget.Filter
+		request.DefaultRefreshInterval = widget.feedRefreshInterval
+
+		if widget.Providers != nil {
+			request.DataPath = widget.Providers.DataPath
+		}
+
+		if err := request.Initialize(); err != nil {
+			return fmt.Errorf("feed %s: %v", request.Url, err)
+		}
+	}
+
+	return nil
+}
+
+// The providers are only set after the widget has been initialized
+func (widget *RSS) SetProviders(providers *Providers) {
+	widget.widgetBase.SetProviders(providers)
+
+	for i := range widget.FeedRequests {
+		widget.FeedRequests[i].DataPath = providers.DataPath
+	}
+}
+
+// The feeds from the OPML source are appended to the ones listed in the config
+// once they've been successfully loaded, failures are retried on the next update
+func (widget *RSS) loadOPML() error {
+	var requests []feed.RSSFeedRequest
+	var err error
+
+	if strings.HasPrefix(widget.OPML, "http://") || strings.HasPrefix(widget.OPML, "https://") {
+		requests, err = feed.FetchOPML(widget.OPML)
+	} else {
+		var contents []byte
+
+		if contents, err = widget.readAssetFile(widget.OPML); err == nil {
+			requests, err = feed.ParseOPML(contents)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("opml: %v", err)
+	}
+
+	if len(widget.OPMLCategories) > 0 {
+		filtered := make([]feed.RSSFeedRequest, 0, len(requests))
+
+		for i := range requests {
+			if slices.ContainsFunc(widget.OPMLCategories, func(category string) bool {
+				return strings.EqualFold(category, requests[i].Category)
+			}) {
+				filtered = append(filtered, requests[i])
+			}
+		}
+
+		requests = filtered
+	}
+
+	widget.mu.Lock()
+	defer widget.mu.Unlock()
+
+	// feeds listed in the config take precedence over the same ones in the OPML
+	for i := range requests {
+		if !slices.ContainsFunc(widget.FeedRequests, func(existing feed.RSSFeedRequest) bool {
+			return existing.Url == requests[i].Url
+		}) {
+			widget.FeedRequests = append(widget.FeedRequests, requests[i])
+		}
+	}
+
+	widget.opmlLoaded = true
+
+	return widget.prepareFeedRequests(widget.FeedRequests)
+}
+
 func (widget *RSS) Update(ctx context.Context) {
+	if widget.OPML != "" && !widget.opmlLoaded {
+		err := widget.loadOPML()
+
+		if err != nil && len(widget.FeedRequests) == 0 {
+			widget.canContinueUpdateAfterHandlingErr(err)
+			return
+		}
+
+		if err != nil {
+			slog.Error("failed to load opml", "widget", widget.GetID(), "error", err)
+		}
+	}
+
 	items, err := feed.GetItemsFromRSSFeeds(widget.FeedRequests)
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
 	}
 
+	var archive feed.RSSFeedItems
+
+	if widget.usesHistory() {
+		widget.mu.Lock()
+		archive = widget.applyHistory(items)
+		widget.mu.Unlock()
+	}
+
+	if widget.Deduplicate {
+		items = items.Cluster(widget.SimilarityThreshold)
+		archive = archive.Cluster(widget.SimilarityThreshold)
+	}
+
 	if len(items) > widget.Limit {
 		items = items[:widget.Limit]
 	}
 
+	// archived items are older than the current ones, so they're shown after
+	// them rather than competing with them for the limit
+	items = append(items, archive...)
+
+	// translating can take a while, so it's done without holding the lock
+	// that requests such as marking items as read have to wait for
+	if widget.TranslateTitles != "" {
+		items = widget.translateItemTitles(ctx, items)
+	}
+
+	widget.mu.Lock()
+	defer widget.mu.Unlock()
+
+	widget.applyItemState(items)
 	widget.Items = items
+	widget.updateUnreadCount()
+}
+
+// Works on a copy since the history keeps the items with their original titles
+func (widget *RSS) translateItemTitles(ctx context.Context, items feed.RSSFeedItems) feed.RSSFeedItems {
+	items = slices.Clone(items)
+	titles := make([]string, len(items))
+
+	for i := range items {
+		titles[i] = items[i].Title
+	}
+
+	titles = widget.translateTitles(ctx, widget.TranslateTitles, titles)
+
+	for i := range items {
+		items[i].Title = titles[i]
+	}
+
+	return items
+}
+
+func (widget *RSS) SyndicationItems() []feed.SyndicationItem {
+	widget.mu.Lock()
+	defer widget.mu.Unlock()
+
+	return widget.Items.ToSyndicationItems()
+}
+
+func (widget *RSS) HandleRequest(w http.ResponseWriter, r *http.Request) {
+	switch r.PathValue("path") {
+	case "opml":
+		widget.handleOPMLRequest(w, r)
+		return
+	case "mark-read":
+		if widget.TrackRead {
+			widget.handleMarkReadRequest(w, r)
+			return
+		}
+	case "reader":
+		if widget.ReaderView {
+			widget.handleReaderRequest(w, r)
+			return
+		}
+	case "playback":
+		if widget.Style == "podcast" {
+			widget.handlePlaybackRequest(w, r)
+			return
+		}
+	}
+
+	http.Error(w, "not found", http.StatusNotFound)
+}
+
+func (widget *RSS) handleOPMLRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	widget.mu.Lock()
+	requests := slices.Clone(widget.FeedRequests)
+	widget.mu.Unlock()
+
+	contents, err := feed.EncodeOPML(widget.Title, requests)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"glance-%d.opml\"", widget.GetID()))
+	w.Write(contents)
+}
+
+func (widget *RSS) handleMarkReadRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	widget.mu.Lock()
+	defer widget.mu.Unlock()
+
+	widget.markRead(r.FormValue("key"))
+	widget.updateUnreadCount()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (widget *RSS) handlePlaybackRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	position, err := strconv.Atoi(r.FormValue("position"))
+
+	if err != nil || position < 0 {
+		http.Error(w, "invalid position", http.StatusBadRequest)
+		return
+	}
+
+	widget.mu.Lock()
+	defer widget.mu.Unlock()
+
+	if !widget.setPlaybackPosition(r.FormValue("key"), position) {
+		http.Error(w, "episode not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Only links of items that are currently displayed can be opened in the
+// reader view, otherwise the endpoint could be used to fetch arbitrary pages
+func (widget *RSS) findItemByLink(link string) (feed.RSSFeedItem, bool) {
+	widget.mu.Lock()
+	defer widget.mu.Unlock()
+
+	for i := range widget.Items {
+		if widget.Items[i].Link == link {
+			return widget.Items[i], true
+		}
+	}
+
+	return feed.RSSFeedItem{}, false
+}
+
+func (widget *RSS) handleReaderRequest(w http.ResponseWriter, r *http.Request) {
+	item, found := widget.findItemByLink(r.URL.Query().Get("link"))
+
+	if !found {
+		http.Error(w, "item not found", http.StatusNotFound)
+		return
+	}
+
+	article, err := feed.FetchArticle(r.Context(), item.Link, widget.renderer)
+
+	data := struct {
+		Item    feed.RSSFeedItem
+		Article *parser.Article
+		Content template.HTML
+		Error   error
+	}{Item: item, Article: article, Error: err}
+
+	if article != nil {
+		data.Content = template.HTML(article.Content)
+	}
+
+	var buffer bytes.Buffer
+
+	if err := assets.RSSReaderTemplate.Execute(&buffer, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buffer.Bytes())
 }
 
 func (widget *RSS) Render() template.HTML {
+	widget.mu.Lock()
+	defer widget.mu.Unlock()
+
 	if widget.Style == "horizontal-cards" {
 		return widget.render(widget, assets.RSSHorizontalCardsTemplate)
 	}
@@ -79,5 +426,9 @@ func (widget *RSS) Render() template.HTML {
 		return widget.render(widget, assets.RSSDetailedListTemplate)
 	}
 
+	if widget.Style == "podcast" {
+		return widget.render(widget, assets.RSSPodcastTemplate)
+	}
+
 	return widget.render(widget, assets.RSSListTemplate)
 }
@@ -0,0 +1,193 @@
+package widget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"time"
+
+	"github.com/glanceapp/glance/internal/assets"
+	"github.com/glanceapp/glance/internal/feed"
+	"github.com/glanceapp/glance/internal/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// Either just a selector or an object with a selector and the attribute to read
+type scraperField struct {
+	Selector  string `yaml:"selector"`
+	Attribute string `yaml:"attribute"`
+}
+
+func (f *scraperField) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&f.Selector)
+	}
+
+	type plain scraperField
+
+	return node.Decode((*plain)(f))
+}
+
+func (f *scraperField) compile() (feed.ScraperField, error) {
+	if f.Selector == "" {
+		return feed.ScraperField{}, nil
+	}
+
+	selector, err := parser.CompileSelector(f.Selector)
+
+	if err != nil {
+		return feed.ScraperField{}, err
+	}
+
+	return feed.ScraperField{Selector: selector, Attribute: f.Attribute}, nil
+}
+
+type Scraper struct {
+	widgetBase       `yaml:",inline"`
+	URL              string            `yaml:"url"`
+	ItemsSelector    string            `yaml:"items"`
+	Timezone         string            `yaml:"timezone"`
+	Renderer         *RendererField    `yaml:"renderer"`
+	Style            string            `yaml:"style"`
+	ThumbnailHeight  float64           `yaml:"thumbnail-height"`
+	CardHeight       float64           `yaml:"card-height"`
+	Limit            int               `yaml:"limit"`
+	CollapseAfter    int               `yaml:"collapse-after"`
+	SingleLineTitles bool              `yaml:"single-line-titles"`
+	Items            feed.RSSFeedItems `yaml:"-"`
+	NoItemsMessage   string            `yaml:"-"`
+	Fields           struct {
+		Title       scraperField `yaml:"title"`
+		Link        scraperField `yaml:"link"`
+		Image       scraperField `yaml:"image"`
+		Time        scraperField `yaml:"time"`
+		Description scraperField `yaml:"description"`
+	} `yaml:"fields"`
+	// the RSS templates are shared but these features are specific to the RSS widget
+	TrackRead   bool `yaml:"-"`
+	ReaderView  bool `yaml:"-"`
+	UnreadCount int  `yaml:"-"`
+	request     *feed.ScraperRequest
+}
+
+func (widget *Scraper) Initialize() error {
+	widget.withTitle("Scraper").withCacheDuration(time.Hour)
+
+	if widget.Limit <= 0 {
+		widget.Limit = 25
+	}
+
+	if widget.CollapseAfter == 0 || widget.CollapseAfter < -1 {
+		widget.CollapseAfter = 5
+	}
+
+	if widget.ThumbnailHeight < 0 {
+		widget.ThumbnailHeight = 0
+	}
+
+	if widget.CardHeight < 0 {
+		widget.CardHeight = 0
+	}
+
+	if parsed, err := url.Parse(widget.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return errors.New("url must be an http or https URL")
+	}
+
+	if widget.ItemsSelector == "" {
+		return errors.New("items is required")
+	}
+
+	if widget.Fields.Title.Selector == "" {
+		return errors.New("fields.title is required")
+	}
+
+	request := &feed.ScraperRequest{URL: widget.URL}
+
+	items, err := parser.CompileSelector(widget.ItemsSelector)
+
+	if err != nil {
+		return fmt.Errorf("items: %v", err)
+	}
+
+	request.Items = items
+
+	fields := []struct {
+		name   string
+		config *scraperField
+		target *feed.ScraperField
+	}{
+		{"title", &widget.Fields.Title, &request.Title},
+		{"link", &widget.Fields.Link, &request.Link},
+		{"image", &widget.Fields.Image, &request.Image},
+		{"time", &widget.Fields.Time, &request.Time},
+		{"description", &widget.Fields.Description, &request.Description},
+	}
+
+	for i := range fields {
+		field, err := fields[i].config.compile()
+
+		if err != nil {
+			return fmt.Errorf("fields.%s: %v", fields[i].name, err)
+		}
+
+		*fields[i].target = field
+	}
+
+	if widget.Timezone != "" {
+		location, err := time.LoadLocation(widget.Timezone)
+
+		if err != nil {
+			return fmt.Errorf("timezone: %v", err)
+		}
+
+		request.Location = location
+	}
+
+	renderer, err := newRenderer(widget.Renderer)
+
+	if err != nil {
+		return fmt.Errorf("renderer: %v", err)
+	}
+
+	request.Renderer = renderer
+	widget.request = request
+	widget.NoItemsMessage = "No items were found on the page."
+
+	return nil
+}
+
+func (widget *Scraper) Update(ctx context.Context) {
+	items, err := feed.FetchScrapedItems(ctx, widget.request)
+
+	if !widget.canContinueUpdateAfterHandlingErr(err) {
+		return
+	}
+
+	if len(items) > widget.Limit {
+		items = items[:widget.Limit]
+	}
+
+	widget.Items = items
+}
+
+func (widget *Scraper) SyndicationItems() []feed.SyndicationItem {
+	return widget.Items.ToSyndicationItems()
+}
+
+func (widget *Scraper) Render() template.HTML {
+	if widget.Style == "horizontal-cards" {
+		return widget.render(widget, assets.RSSHorizontalCardsTemplate)
+	}
+
+	if widget.Style == "horizontal-cards-2" {
+		return widget.render(widget, assets.RSSHorizontalCards2Template)
+	}
+
+	if widget.Style == "detailed-list" {
+		return widget.render(widget, assets.RSSDetailedListTemplate)
+	}
+
+	return widget.render(widget, assets.RSSListTemplate)
+}
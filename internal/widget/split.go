@@ -0,0 +1,53 @@
+package widget
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"time"
+
+	"github.com/glanceapp/glance/internal/assets"
+)
+
+// Places widgets next to each other within a single column, wrapping onto
+// new rows after MaxColumns and stacking them on narrow screens
+type Split struct {
+	widgetBase          `yaml:",inline"`
+	containerWidgetBase `yaml:",inline"`
+	MaxColumns          int `yaml:"max-columns"`
+}
+
+func (widget *Split) Initialize() error {
+	widget.withTitle("Split").withError(nil)
+	widget.HideHeader = true
+
+	if widget.LazyLoad {
+		return errors.New("lazy-load is not supported by the split widget since all of its widgets are visible")
+	}
+
+	if widget.MaxColumns < 0 {
+		return errors.New("max-columns cannot be negative")
+	}
+
+	if widget.MaxColumns == 0 || widget.MaxColumns > len(widget.Widgets) {
+		widget.MaxColumns = len(widget.Widgets)
+	}
+
+	return widget.initializeWidgets(false)
+}
+
+func (widget *Split) Update(ctx context.Context) {
+	widget.updateWidgets(ctx)
+}
+
+func (widget *Split) SetProviders(providers *Providers) {
+	widget.setWidgetsProviders(providers)
+}
+
+func (widget *Split) RequiresUpdate(now *time.Time) bool {
+	return widget.widgetsRequireUpdate(now)
+}
+
+func (widget *Split) Render() template.HTML {
+	return widget.render(widget, assets.SplitTemplate)
+}
@@ -0,0 +1,42 @@
+package widget
+
+import (
+	"context"
+	"errors"
+
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+var errNoTranslator = errors.New("translate-titles requires a translator to be configured in the ai section")
+
+// Titles that couldn't be translated are kept as they are and reported
+// through the widget's notice rather than failing the whole update, alongside
+// any notice the update already set such as missing some of the content
+func (w *widgetBase) translateTitles(ctx context.Context, language LanguageField, titles []string) []string {
+	if w.Providers == nil || w.Providers.Translator == nil {
+		w.withNotice(errors.Join(w.Notice, errNoTranslator))
+		return titles
+	}
+
+	translated, err := feed.TranslateTexts(ctx, w.Providers.Translator, titles, string(language))
+
+	if err != nil {
+		w.withNotice(errors.Join(w.Notice, err))
+	}
+
+	return translated
+}
+
+func (w *widgetBase) translateForumPostTitles(ctx context.Context, language LanguageField, posts feed.ForumPosts) {
+	titles := make([]string, len(posts))
+
+	for i := range posts {
+		titles[i] = posts[i].Title
+	}
+
+	titles = w.translateTitles(ctx, language, titles)
+
+	for i := range posts {
+		posts[i].Title = titles[i]
+	}
+}
@@ -2,6 +2,8 @@ package widget
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"html/template"
 	"time"
 
@@ -11,13 +13,20 @@ import (
 
 type Videos struct {
 	widgetBase        `yaml:",inline"`
-	Videos            feed.Videos `yaml:"-"`
-	VideoUrlTemplate  string      `yaml:"video-url-template"`
-	Style             string      `yaml:"style"`
-	CollapseAfterRows int         `yaml:"collapse-after-rows"`
-	Channels          []string    `yaml:"channels"`
-	Limit             int         `yaml:"limit"`
-	IncludeShorts     bool        `yaml:"include-shorts"`
+	Videos            feed.Videos   `yaml:"-"`
+	VideoUrlTemplate  string        `yaml:"video-url-template"`
+	Style             string        `yaml:"style"`
+	CollapseAfterRows int           `yaml:"collapse-after-rows"`
+	Channels          []string      `yaml:"channels"`
+	Limit             int           `yaml:"limit"`
+	IncludeShorts     bool          `yaml:"include-shorts"`
+	MinDuration       DurationField `yaml:"min-duration"`
+	MaxDuration       DurationField `yaml:"max-duration"`
+	HidePremieres     bool          `yaml:"hide-premieres"`
+	PerChannelLimit   int           `yaml:"per-channel-limit"`
+	MinPerChannel     int           `yaml:"min-per-channel"`
+	Mix               string        `yaml:"mix"`
+	channels          []feed.VideoChannel
 }
 
 func (widget *Videos) Initialize() error {
@@ -31,22 +40,61 @@ func (widget *Videos) Initialize() error {
 		widget.CollapseAfterRows = 4
 	}
 
+	if widget.MaxDuration > 0 && widget.MinDuration >= widget.MaxDuration {
+		return errors.New("min-duration must be less than max-duration")
+	}
+
+	if widget.PerChannelLimit < 0 || widget.MinPerChannel < 0 {
+		return errors.New("per-channel-limit and min-per-channel cannot be negative")
+	}
+
+	if widget.Mix != "" && widget.Mix != "newest" && widget.Mix != "round-robin" {
+		return fmt.Errorf("invalid mix %s, must be either newest or round-robin", widget.Mix)
+	}
+
+	widget.channels = make([]feed.VideoChannel, 0, len(widget.Channels))
+
+	for _, entry := range widget.Channels {
+		channel, err := feed.ParseVideoChannel(entry)
+
+		if err != nil {
+			return fmt.Errorf("channels: %v", err)
+		}
+
+		widget.channels = append(widget.channels, channel)
+	}
+
+	// otherwise the guaranteed videos get cut to the limit, dropping whole channels
+	if widget.MinPerChannel*len(widget.channels) > widget.Limit {
+		return fmt.Errorf("min-per-channel of %d for %d channels exceeds the limit of %d", widget.MinPerChannel, len(widget.channels), widget.Limit)
+	}
+
 	return nil
 }
 
 func (widget *Videos) Update(ctx context.Context) {
-	//videos, err := feed.FetchYoutubeChannelUploads(widget.Channels, widget.VideoUrlTemplate, widget.IncludeShorts)
-	videos, err := feed.FetchBilibiliChannelUploads(widget.Channels, widget.VideoUrlTemplate, widget.IncludeShorts)
+	videos, err := feed.FetchVideoChannelUploads(widget.channels, feed.VideoFetchOptions{
+		VideoUrlTemplate: widget.VideoUrlTemplate,
+		IncludeShorts:    widget.IncludeShorts,
+		MinDuration:      time.Duration(widget.MinDuration),
+		MaxDuration:      time.Duration(widget.MaxDuration),
+		HideUpcoming:     widget.HidePremieres,
+	})
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
 	}
 
-	if len(videos) > widget.Limit {
-		videos = videos[:widget.Limit]
-	}
+	widget.Videos = videos.Mix(feed.VideoMixOptions{
+		Limit:           widget.Limit,
+		PerChannelLimit: widget.PerChannelLimit,
+		MinPerChannel:   widget.MinPerChannel,
+		RoundRobin:      widget.Mix == "round-robin",
+	})
+}
 
-	widget.Videos = videos
+func (widget *Videos) SyndicationItems() []feed.SyndicationItem {
+	return widget.Videos.ToSyndicationItems()
 }
 
 func (widget *Videos) Render() template.HTML {
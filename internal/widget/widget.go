@@ -6,13 +6,17 @@ import (
 	"errors"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"log/slog"
 	"math"
 	"net/http"
+	"os"
+	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/glanceapp/glance/internal/feed"
+	"github.com/glanceapp/glance/internal/tool"
 
 	"gopkg.in/yaml.v3"
 )
@@ -65,8 +69,18 @@ func New(widgetType string) (Widget, error) {
 		widget = &Extension{}
 	case "group":
 		widget = &Group{}
+	case "accordion":
+		widget = &Accordion{}
+	case "split":
+		widget = &Split{}
 	case "dns-stats":
 		widget = &DNSStats{}
+	case "custom-api":
+		widget = &CustomAPI{}
+	case "scraper":
+		widget = &Scraper{}
+	case "digest":
+		widget = &Digest{}
 	default:
 		return nil, fmt.Errorf("unknown widget type: %s", widgetType)
 	}
@@ -129,29 +143,36 @@ const (
 	cacheTypeInfinite cacheType = iota
 	cacheTypeDuration
 	cacheTypeOnTheHour
+	cacheTypeSchedule
 )
 
 type widgetBase struct {
-	ID                  uint64        `yaml:"-"`
-	Providers           *Providers    `yaml:"-"`
-	Type                string        `yaml:"type"`
-	Title               string        `yaml:"title"`
-	TitleURL            string        `yaml:"title-url"`
-	CSSClass            string        `yaml:"css-class"`
-	CustomCacheDuration DurationField `yaml:"cache"`
-	ContentAvailable    bool          `yaml:"-"`
-	Error               error         `yaml:"-"`
-	Notice              error         `yaml:"-"`
-	templateBuffer      bytes.Buffer  `yaml:"-"`
-	cacheDuration       time.Duration `yaml:"-"`
-	cacheType           cacheType     `yaml:"-"`
-	nextUpdate          time.Time     `yaml:"-"`
-	updateRetriedTimes  int           `yaml:"-"`
-	HideHeader          bool          `yaml:"-"`
+	ID                  uint64          `yaml:"-"`
+	Providers           *Providers      `yaml:"-"`
+	Type                string          `yaml:"type"`
+	Title               string          `yaml:"title"`
+	TitleURL            string          `yaml:"title-url"`
+	CSSClass            string          `yaml:"css-class"`
+	CustomCacheDuration DurationField   `yaml:"cache"`
+	ContentAvailable    bool            `yaml:"-"`
+	Error               error           `yaml:"-"`
+	Notice              error           `yaml:"-"`
+	templateBuffer      bytes.Buffer    `yaml:"-"`
+	cacheDuration       time.Duration   `yaml:"-"`
+	cacheType           cacheType       `yaml:"-"`
+	schedule            []time.Duration `yaml:"-"`
+	nextUpdate          time.Time       `yaml:"-"`
+	updateRetriedTimes  int             `yaml:"-"`
+	HideHeader          bool            `yaml:"-"`
 }
 
 type Providers struct {
 	AssetResolver func(string) string
+	AssetsPath    string
+	DataPath      string
+	// nil when the ai section isn't configured
+	Translator tool.Translator
+	Summarizer tool.Summarizer
 }
 
 func (w *widgetBase) RequiresUpdate(now *time.Time) bool {
@@ -186,14 +207,88 @@ func (widget *widgetBase) HandleRequest(w http.ResponseWriter, r *http.Request)
 	http.Error(w, "not implemented", http.StatusNotImplemented)
 }
 
+// Implemented by list-like widgets that serve their content as an Atom feed
+// from feed.xml and as a JSON Feed from feed.json. The items are taken while
+// the widget's page is locked, so that an update can't replace them meanwhile
+type SyndicationFeedSource interface {
+	SyndicationItems() []feed.SyndicationItem
+	ServeSyndicationFeed(http.ResponseWriter, *http.Request, []feed.SyndicationItem)
+}
+
+// Responds with not found for any path other than feed.xml and feed.json
+func (w *widgetBase) ServeSyndicationFeed(wr http.ResponseWriter, r *http.Request, items []feed.SyndicationItem) {
+	path := r.PathValue("path")
+
+	if path != "feed.xml" && path != "feed.json" {
+		http.Error(wr, "not found", http.StatusNotFound)
+		return
+	}
+
+	scheme := "http"
+
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-Proto"); forwarded != "" {
+		scheme = forwarded
+	}
+
+	syndicationFeed := feed.SyndicationFeed{
+		Title:   w.Title,
+		HomeURL: w.TitleURL,
+		FeedURL: scheme + "://" + r.Host + r.URL.Path,
+		Items:   items,
+	}
+
+	var contents []byte
+	var err error
+
+	if path == "feed.xml" {
+		wr.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+		contents, err = syndicationFeed.EncodeAtom()
+	} else {
+		wr.Header().Set("Content-Type", "application/feed+json; charset=utf-8")
+		contents, err = syndicationFeed.EncodeJSONFeed()
+	}
+
+	if err != nil {
+		wr.Header().Del("Content-Type")
+		http.Error(wr, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	wr.Write(contents)
+}
+
 func (w *widgetBase) GetType() string {
 	return w.Type
 }
 
+func (w *widgetBase) getTitle() string {
+	return w.Title
+}
+
 func (w *widgetBase) SetProviders(providers *Providers) {
 	w.Providers = providers
 }
 
+// Reads a file from the server's assets path, the path can optionally
+// be prefixed with /assets/ like it would be when used in a URL
+func (w *widgetBase) readAssetFile(path string) ([]byte, error) {
+	if w.Providers == nil || w.Providers.AssetsPath == "" {
+		return nil, errors.New("the server assets-path must be set to read files from it")
+	}
+
+	name := strings.TrimPrefix(path, "/assets/")
+
+	if !fs.ValidPath(name) {
+		return nil, fmt.Errorf("invalid path %s", path)
+	}
+
+	return fs.ReadFile(os.DirFS(w.Providers.AssetsPath), name)
+}
+
 func (w *widgetBase) render(data any, t *template.Template) template.HTML {
 	w.templateBuffer.Reset()
 	err := t.Execute(&w.templateBuffer, data)
@@ -255,6 +350,14 @@ func (w *widgetBase) withCacheOnTheHour() *widgetBase {
 	return w
 }
 
+// Updates at the given times of the day, in the server's local time
+func (w *widgetBase) withCacheSchedule(times []time.Duration) *widgetBase {
+	w.cacheType = cacheTypeSchedule
+	w.schedule = times
+
+	return w
+}
+
 func (w *widgetBase) withNotice(err error) *widgetBase {
 	w.Notice = err
 
@@ -318,9 +421,33 @@ func (w *widgetBase) getNextUpdateTime() time.Time {
 		) * time.Second)
 	}
 
+	if w.cacheType == cacheTypeSchedule {
+		return nextScheduledTime(now, w.schedule)
+	}
+
 	return time.Time{}
 }
 
+// The first of the times of the day that comes after the given time
+func nextScheduledTime(after time.Time, times []time.Duration) time.Time {
+	var next time.Time
+
+	for _, at := range times {
+		hour, minute := int(at/time.Hour), int(at%time.Hour/time.Minute)
+		candidate := time.Date(after.Year(), after.Month(), after.Day(), hour, minute, 0, 0, after.Location())
+
+		if !candidate.After(after) {
+			candidate = time.Date(after.Year(), after.Month(), after.Day()+1, hour, minute, 0, 0, after.Location())
+		}
+
+		if next.IsZero() || candidate.Before(next) {
+			next = candidate
+		}
+	}
+
+	return next
+}
+
 func (w *widgetBase) scheduleNextUpdate() *widgetBase {
 	w.nextUpdate = w.getNextUpdateTime()
 	w.updateRetriedTimes = 0